@@ -0,0 +1,83 @@
+package hashmap
+
+import "runtime"
+
+const batchSize = 16 // Number of keys hashed ahead of probing by batched operations
+
+// Look up all the given keys, storing their value and whether they were found at the same index in out and found.
+// It panics if out or found is shorter than keys.
+//
+// Keys are processed by batches: all keys of a batch are hashed, their ideal slots are loaded so that cache misses
+// overlap, then they are looked up. This hides memory latency on tables larger than the CPU caches.
+func (m *Hashmap[TKey, TValue]) GetMany(keys []TKey, out []TValue, found []bool) {
+	if len(out) < len(keys) || len(found) < len(keys) {
+		panic("hashmap: GetMany output slices are shorter than keys")
+	}
+	if m.length == 0 {
+		clear(out[:len(keys)])
+		clear(found[:len(keys)])
+		return
+	}
+
+	var hashes [batchSize]uint64
+	var prefetched bool
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+		for i, key := range batch {
+			hashes[i] = m.hash(key)
+		}
+		prefetched = prefetched != m.prefetch(hashes[:len(batch)])
+		for i, key := range batch {
+			out[start+i], found[start+i] = m.tryGet(key, hashes[i])
+		}
+	}
+	runtime.KeepAlive(prefetched) // Keep the slot loads from being optimized away
+}
+
+// Insert or update all the given keys, with the value at the same index in values. It panics if lengths differ.
+//
+// Keys are processed by batches, see GetMany.
+func (m *Hashmap[TKey, TValue]) SetMany(keys []TKey, values []TValue) {
+	if len(keys) != len(values) {
+		panic("hashmap: SetMany keys and values lengths differ")
+	}
+	if m.hooks != nil {
+		// Hooks are called after each change
+		for i, key := range keys {
+			m.Set(key, values[i])
+		}
+		return
+	}
+	m.startWrite()
+	defer m.endWrite()
+	if len(m.storage) == 0 && m.engine == nil {
+		m.lazyInit()
+	}
+
+	var hashes [batchSize]uint64
+	var prefetched bool
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+		for i, key := range batch {
+			hashes[i] = m.hash(key)
+		}
+		prefetched = prefetched != m.prefetch(hashes[:len(batch)])
+		for i, key := range batch {
+			m.set(key, hashes[i], values[start+i])
+		}
+	}
+	runtime.KeepAlive(prefetched)
+}
+
+// Load the ideal slots of the given hashes, only the RobinHood layout storage is prefetched.
+func (m *Hashmap[TKey, TValue]) prefetch(hashes []uint64) bool {
+	if m.engine != nil {
+		return false
+	}
+	mask := uint64(len(m.storage) - 1)
+	var alive bool
+	for _, hash := range hashes {
+		alive = alive != m.storage[hash&mask].alive
+	}
+	return alive
+}
@@ -0,0 +1,95 @@
+package hashmap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetManySetMany(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[int, int](l.layout), WithInitialCapacity[int, int](8))
+		keys := make([]int, 1000)
+		values := make([]int, 1000)
+		for i := range keys {
+			keys[i] = i * 2
+			values[i] = i
+		}
+		m.SetMany(keys, values)
+		if m.Len() != len(keys) {
+			t.Errorf("invalid length for layout=%s. expected=%d, got=%d", l.name, len(keys), m.Len())
+		}
+
+		lookups := make([]int, 2000)
+		for i := range lookups {
+			lookups[i] = i
+		}
+		out := make([]int, len(lookups))
+		found := make([]bool, len(lookups))
+		m.GetMany(lookups, out, found)
+		for i, key := range lookups {
+			expectedFound := key%2 == 0
+			expectedValue := 0
+			if expectedFound {
+				expectedValue = key / 2
+			}
+			if found[i] != expectedFound || out[i] != expectedValue {
+				t.Errorf("invalid lookup for layout=%s, key=%d. expected=(%d, %t), got=(%d, %t)", l.name, key, expectedValue, expectedFound, out[i], found[i])
+			}
+		}
+	}
+}
+
+func TestGetManyZeroValue(t *testing.T) {
+	var m Hashmap[int, int]
+	out := []int{1, 2}
+	found := []bool{true, true}
+	m.GetMany([]int{1, 2}, out, found)
+	if out[0] != 0 || out[1] != 0 || found[0] || found[1] {
+		t.Errorf("invalid lookups on empty hashmap: out=%v, found=%v", out, found)
+	}
+
+	m.SetMany([]int{1, 2}, []int{10, 20})
+	if m.Get(1) != 10 || m.Get(2) != 20 {
+		t.Errorf("invalid entries: %v", m.GetEntries())
+	}
+}
+
+func TestGetManyShortOutput(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("short output slices not detected")
+		}
+	}()
+	m := New[int, int]()
+	m.GetMany([]int{1, 2}, make([]int, 1), make([]bool, 2))
+}
+
+// GetMany is a read: concurrent calls must not race, see go test -race.
+func TestGetManyConcurrent(t *testing.T) {
+	m := New[int, int]()
+	keys := make([]int, 100)
+	for i := range keys {
+		keys[i] = i
+		m.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out := make([]int, len(keys))
+			found := make([]bool, len(keys))
+			for range 100 {
+				m.GetMany(keys, out, found)
+				for i := range keys {
+					if out[i] != i || !found[i] {
+						t.Errorf("invalid lookup for key=%d. got=(%d, %t)", i, out[i], found[i])
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -3,8 +3,11 @@ package hashmap
 import (
 	"fmt"
 	"math/rand"
+	"runtime"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func BenchmarkGet(b *testing.B) {
@@ -41,3 +44,398 @@ func BenchmarkGet(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkFrozenGet(b *testing.B) {
+	for _, entriesCount := range []int{100, 1000, 10_000, 100_000, 1_000_000} {
+		key := strconv.Itoa(rand.Intn(entriesCount))
+		m := New[string, int]()
+		for i := range entriesCount {
+			m.Set(strconv.Itoa(i), 0)
+		}
+		frozen := m.Freeze()
+
+		b.Run(fmt.Sprintf("size_%d", entriesCount), func(b *testing.B) {
+			b.Run("Hmap", func(b *testing.B) {
+				for range b.N {
+					_ = m.Get(key)
+				}
+			})
+			b.Run("Frozen", func(b *testing.B) {
+				for range b.N {
+					_ = frozen.Get(key)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLayoutGet(b *testing.B) {
+	for _, entriesCount := range []int{1000, 100_000, 1_000_000} {
+		keys := make([]string, entriesCount)
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+		rand.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+		b.Run(fmt.Sprintf("size_%d", entriesCount), func(b *testing.B) {
+			for _, l := range layouts {
+				b.Run(l.name, func(b *testing.B) {
+					m := New(WithLayout[string, int](l.layout))
+					for i, key := range keys {
+						m.Set(key, i)
+					}
+					b.ResetTimer()
+
+					for i := range b.N {
+						_ = m.Get(keys[i%entriesCount])
+					}
+				})
+			}
+			b.Run("Native map", func(b *testing.B) {
+				m := map[string]int{}
+				for i, key := range keys {
+					m[key] = i
+				}
+				b.ResetTimer()
+
+				for i := range b.N {
+					_ = m[keys[i%entriesCount]]
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLayoutSet(b *testing.B) {
+	for _, entriesCount := range []int{1000, 100_000, 1_000_000} {
+		keys := make([]string, entriesCount)
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+
+		b.Run(fmt.Sprintf("size_%d", entriesCount), func(b *testing.B) {
+			for _, l := range layouts {
+				b.Run(l.name, func(b *testing.B) {
+					for range b.N {
+						m := New(WithLayout[string, int](l.layout))
+						for i, key := range keys {
+							m.Set(key, i)
+						}
+					}
+				})
+			}
+			b.Run("Native map", func(b *testing.B) {
+				for range b.N {
+					m := map[string]int{}
+					for i, key := range keys {
+						m[key] = i
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLargeValues(b *testing.B) {
+	for _, entriesCount := range []int{10_000, 100_000} {
+		keys := rand.Perm(entriesCount * 2) // Second half is used for misses
+
+		b.Run(fmt.Sprintf("size_%d", entriesCount), func(b *testing.B) {
+			for _, l := range []Layout{RobinHood, RobinHoodSoA} {
+				m := New(WithLayout[int, [256]byte](l))
+				for _, key := range keys[:entriesCount] {
+					m.Set(key, [256]byte{})
+				}
+				name := "RobinHood"
+				if l == RobinHoodSoA {
+					name = "RobinHoodSoA"
+				}
+
+				b.Run(name+"/hit", func(b *testing.B) {
+					for i := range b.N {
+						_, _ = m.TryGet(keys[i%entriesCount])
+					}
+				})
+				b.Run(name+"/miss", func(b *testing.B) {
+					for i := range b.N {
+						_, _ = m.TryGet(keys[entriesCount+i%entriesCount])
+					}
+				})
+			}
+		})
+	}
+}
+
+// Measure the duration of a full garbage collection while the hashmap is alive.
+func BenchmarkStringKeyedGC(b *testing.B) {
+	const entriesCount = 1_000_000
+
+	b.Run("Hmap", func(b *testing.B) {
+		m := New[string, int]()
+		for i := range entriesCount {
+			m.Set(strconv.Itoa(i), i)
+		}
+		b.ResetTimer()
+
+		for range b.N {
+			runtime.GC()
+		}
+		runtime.KeepAlive(m)
+	})
+	b.Run("StringKeyed", func(b *testing.B) {
+		m := NewStringKeyed[int]()
+		for i := range entriesCount {
+			m.Set(strconv.Itoa(i), i)
+		}
+		b.ResetTimer()
+
+		for range b.N {
+			runtime.GC()
+		}
+		runtime.KeepAlive(m)
+	})
+}
+
+func BenchmarkIntGet(b *testing.B) {
+	for _, entriesCount := range []int{1000, 100_000} {
+		keySets := []struct {
+			name string
+			keys []uint64
+		}{
+			{"dense", make([]uint64, entriesCount)},
+			{"sparse", make([]uint64, entriesCount)},
+		}
+		for i := range entriesCount {
+			keySets[0].keys[i] = uint64(i)
+			keySets[1].keys[i] = rand.Uint64()
+		}
+
+		for _, keySet := range keySets {
+			keys := keySet.keys
+			b.Run(fmt.Sprintf("%s_%d", keySet.name, entriesCount), func(b *testing.B) {
+				b.Run("Hmap", func(b *testing.B) {
+					m := New[uint64, int]()
+					for _, key := range keys {
+						m.Set(key, 0)
+					}
+					b.ResetTimer()
+
+					for i := range b.N {
+						_ = m.Get(keys[i%entriesCount])
+					}
+				})
+				b.Run("IntHmap", func(b *testing.B) {
+					m := NewInt[uint64, int]()
+					for _, key := range keys {
+						m.Set(key, 0)
+					}
+					b.ResetTimer()
+
+					for i := range b.N {
+						_ = m.Get(keys[i%entriesCount])
+					}
+				})
+				b.Run("Native map", func(b *testing.B) {
+					m := map[uint64]int{}
+					for _, key := range keys {
+						m[key] = 0
+					}
+					b.ResetTimer()
+
+					for i := range b.N {
+						_ = m[keys[i%entriesCount]]
+					}
+				})
+			})
+		}
+	}
+}
+
+// Create a map and insert 3 entries.
+func BenchmarkTinyMap(b *testing.B) {
+	b.Run("Hmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			m := New[int, int]()
+			for i := range 3 {
+				m.Set(i, i)
+			}
+		}
+	})
+	b.Run("SmallHmap", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var m SmallHashmap[int, int]
+			for i := range 3 {
+				m.Set(i, i)
+			}
+		}
+	})
+	b.Run("Native map", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			m := map[int]int{}
+			for i := range 3 {
+				m[i] = i
+			}
+		}
+	})
+}
+
+// Look up batches of random keys in a table larger than the CPU caches.
+func BenchmarkGetMany(b *testing.B) {
+	const entriesCount = 4_000_000
+	const batchLength = 1000
+
+	m := New(WithInitialCapacity[uint64, uint64](1 << 23))
+	keys := make([]uint64, entriesCount)
+	for i := range keys {
+		keys[i] = rand.Uint64()
+	}
+	m.SetMany(keys, keys)
+	rand.Shuffle(entriesCount, func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	out := make([]uint64, batchLength)
+	found := make([]bool, batchLength)
+
+	// Batches are taken from the whole keys set, so that slots are not cached from a previous iteration
+	b.Run("TryGet", func(b *testing.B) {
+		for n := range b.N {
+			start := n * batchLength % entriesCount
+			for i, key := range keys[start : start+batchLength] {
+				out[i], found[i] = m.TryGet(key)
+			}
+		}
+	})
+	b.Run("GetMany", func(b *testing.B) {
+		for n := range b.N {
+			start := n * batchLength % entriesCount
+			m.GetMany(keys[start:start+batchLength], out, found)
+		}
+	})
+}
+
+// Concurrent reads while a writer publishes a change every millisecond.
+func BenchmarkReadMostlyGet(b *testing.B) {
+	const entriesCount = 100_000
+	m := NewReadMostly[int, int]()
+	m.Update(func(batch *ReadMostlyBatch[int, int]) {
+		for i := range entriesCount {
+			batch.Set(i, i)
+		}
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				m.Set(i%entriesCount, i)
+			}
+		}
+	}()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := rand.Int()
+		for pb.Next() {
+			_ = m.Get(i % entriesCount)
+			i++
+		}
+	})
+}
+
+// Concurrent mixed operations, 90% reads.
+func BenchmarkConcurrent(b *testing.B) {
+	const entriesCount = 100_000
+
+	b.Run("ConcurrentHmap", func(b *testing.B) {
+		m := NewConcurrent[int, int]()
+		for i := range entriesCount {
+			m.Set(i, i)
+		}
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			i := rand.Int()
+			for pb.Next() {
+				if i%10 == 0 {
+					m.Set(i%entriesCount, i)
+				} else {
+					_ = m.Get(i % entriesCount)
+				}
+				i++
+			}
+		})
+	})
+	b.Run("Mutex Hmap", func(b *testing.B) {
+		var mu sync.RWMutex
+		m := New[int, int]()
+		for i := range entriesCount {
+			m.Set(i, i)
+		}
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			i := rand.Int()
+			for pb.Next() {
+				if i%10 == 0 {
+					mu.Lock()
+					m.Set(i%entriesCount, i)
+					mu.Unlock()
+				} else {
+					mu.RLock()
+					_ = m.Get(i % entriesCount)
+					mu.RUnlock()
+				}
+				i++
+			}
+		})
+	})
+	b.Run("sync.Map", func(b *testing.B) {
+		var m sync.Map
+		for i := range entriesCount {
+			m.Store(i, i)
+		}
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			i := rand.Int()
+			for pb.Next() {
+				if i%10 == 0 {
+					m.Store(i%entriesCount, i)
+				} else {
+					_, _ = m.Load(i % entriesCount)
+				}
+				i++
+			}
+		})
+	})
+}
+
+func BenchmarkClone(b *testing.B) {
+	m := New[string, int]()
+	for i := range 100_000 {
+		m.Set(strconv.Itoa(i), i)
+	}
+
+	b.Run("Clone", func(b *testing.B) {
+		for range b.N {
+			m.Clone()
+		}
+	})
+	b.Run("Snapshot", func(b *testing.B) {
+		for range b.N {
+			m.Snapshot()
+		}
+	})
+	b.Run("GetEntries and Set", func(b *testing.B) {
+		for range b.N {
+			clone := New(WithInitialCapacity[string, int](uint(m.capacity())))
+			for _, kv := range m.GetEntries() {
+				clone.Set(kv.Key, kv.Value)
+			}
+		}
+	})
+}
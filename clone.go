@@ -0,0 +1,93 @@
+package hashmap
+
+import "slices"
+
+// Get a copy of the hashmap, with the same configuration.
+//
+// The storage is copied as is: the hash function and seed are kept, so no key is hashed again.
+// Hooks registered with OnChange are not copied, the incremental fingerprint is.
+func (m *Hashmap[TKey, TValue]) Clone() *Hashmap[TKey, TValue] {
+	m.checkRead()
+	clone := m.copyConfig()
+	if m.engine != nil {
+		clone.engine = m.engine.clone(clone.hash)
+	} else if m.storage != nil {
+		clone.storage = clone.allocStorage(len(m.storage))
+		copy(clone.storage, m.storage)
+	}
+	return clone
+}
+
+// Get a read-only view of the current content of the hashmap, which is not affected by later writes.
+//
+// The snapshot shares the storage of the hashmap until one of them is written: the written one then copies the storage
+// before changing it. A snapshot can therefore be read by other goroutines while the hashmap keeps being written,
+// as long as the snapshot itself is not written. Snapshots of off-heap hashmaps are clones, see Clone.
+func (m *Hashmap[TKey, TValue]) Snapshot() *Hashmap[TKey, TValue] {
+	if m.offHeap {
+		return m.Clone()
+	}
+	m.checkRead()
+	snapshot := m.copyConfig()
+	snapshot.storage = m.storage
+	snapshot.engine = m.engine
+	if m.storage != nil || m.engine != nil {
+		m.shared = true
+		snapshot.shared = true
+	}
+	return snapshot
+}
+
+// Create an empty hashmap with the same configuration and length, for Clone and Snapshot.
+func (m *Hashmap[TKey, TValue]) copyConfig() *Hashmap[TKey, TValue] {
+	clone := &Hashmap[TKey, TValue]{
+		layout:          m.layout,
+		initialCapacity: m.initialCapacity,
+		length:          m.length,
+		loadFactor:      m.loadFactor,
+		maxProbe:        m.maxProbe,
+		hashFunc:        m.hashFunc,
+		hashSeed:        m.hashSeed,
+		offHeap:         m.offHeap,
+	}
+	if m.fingerprint != nil {
+		fingerprint := *m.fingerprint
+		clone.enableFingerprint(&fingerprint)
+	}
+	return clone
+}
+
+// Copy the storage shared with a snapshot, before writing it.
+func (m *Hashmap[TKey, TValue]) unshare() {
+	m.shared = false
+	if m.engine != nil {
+		m.engine = m.engine.clone(m.hash)
+		return
+	}
+	m.storage = slices.Clone(m.storage)
+}
+
+func (t *swissTable[TKey, TValue]) clone(hash func(TKey) uint64) engine[TKey, TValue] {
+	clone := *t
+	clone.ctrls = slices.Clone(t.ctrls)
+	clone.slots = slices.Clone(t.slots)
+	clone.hash = hash
+	return &clone
+}
+
+func (t *cuckooTable[TKey, TValue]) clone(hash func(TKey) uint64) engine[TKey, TValue] {
+	clone := *t
+	clone.slots = slices.Clone(t.slots)
+	clone.stash = slices.Clone(t.stash)
+	clone.hash = hash
+	return &clone
+}
+
+func (t *soaTable[TKey, TValue]) clone(hash func(TKey) uint64) engine[TKey, TValue] {
+	clone := *t
+	clone.meta = slices.Clone(t.meta)
+	clone.keys = slices.Clone(t.keys)
+	clone.values = slices.Clone(t.values)
+	clone.hash = hash
+	return &clone
+}
@@ -0,0 +1,82 @@
+package hashmap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[int, int](l.layout))
+		for i := range 1000 {
+			m.Set(i, i)
+		}
+		clone := m.Clone()
+		m.Set(0, -1)
+		m.Delete(1)
+		clone.Set(2, -2)
+		for i := range 1100 {
+			clone.Set(i, i*2)
+		}
+
+		if m.Len() != 999 || m.Get(0) != -1 || m.Get(2) != 2 {
+			t.Errorf("hashmap changed by its clone for layout=%s", l.name)
+		}
+		if clone.Len() != 1100 {
+			t.Errorf("invalid clone length for layout=%s. expected=1100, got=%d", l.name, clone.Len())
+		}
+		for i := range 1100 {
+			if value := clone.Get(i); value != i*2 {
+				t.Errorf("invalid clone value for layout=%s, key=%d. expected=%d, got=%d", l.name, i, i*2, value)
+			}
+		}
+	}
+
+	var zero Hashmap[string, int]
+	clone := zero.Clone()
+	clone.Set("key", 1)
+	if zero.Len() != 0 || clone.Get("key") != 1 {
+		t.Error("invalid clone of a zero value hashmap")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[int, int](l.layout))
+		for i := range 1000 {
+			m.Set(i, i)
+		}
+		snapshot := m.Snapshot()
+		if m.engine != nil && snapshot.engine != m.engine || m.engine == nil && &snapshot.storage[0] != &m.storage[0] {
+			t.Errorf("storage not shared for layout=%s", l.name)
+		}
+
+		// Read the snapshot while the hashmap is written
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if value := snapshot.Get(i); value != i {
+					t.Errorf("snapshot changed for layout=%s, key=%d. expected=%d, got=%d", l.name, i, i, value)
+				}
+			}
+		}()
+		for i := range 1000 {
+			m.Set(i, -i)
+		}
+		m.Clear()
+		wg.Wait()
+
+		if m.Len() != 0 || snapshot.Len() != 1000 {
+			t.Errorf("invalid lengths for layout=%s. expected=(0, 1000), got=(%d, %d)", l.name, m.Len(), snapshot.Len())
+		}
+		// The snapshot is writable too
+		second := snapshot.Snapshot()
+		snapshot.Clear()
+		snapshot.Set(1, 1)
+		if snapshot.Len() != 1 || second.Len() != 1000 || second.Get(999) != 999 {
+			t.Errorf("invalid snapshot writes for layout=%s", l.name)
+		}
+	}
+}
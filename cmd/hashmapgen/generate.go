@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/valsov/hashmap/hasher"
+	"github.com/valsov/hashmap/internal/mph"
+)
+
+const maxSeeds = 1024 // Number of seeds tried before giving up on building a perfect hash
+
+// Generated file template.
+//
+// The lookup inlines mph.Index, both must stay in sync.
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by hashmapgen; DO NOT EDIT.
+// Source: {{.Source}}
+
+package {{.Package}}
+{{if .Entries}}
+import "github.com/valsov/hashmap/hasher"
+{{end}}
+// Static lookup table, see the {{.Type}} methods.
+var {{.Name}} {{.Type}}
+
+// Static lookup table, backed by a minimal perfect hash function.
+type {{.Type}} struct{}
+{{if .Entries}}
+const {{.Prefix}}Seed = {{.Seed}}
+
+var {{.Prefix}}Displacements = [...]uint32{ {{- range .Displacements}}{{.}}, {{end -}} }
+{{end}}
+var {{.Prefix}}Entries = [...]struct {
+	key   string
+	value {{.ValueType}}
+}{
+{{- range .Entries}}
+	{ {{- .Key}}, {{.Value -}} },
+{{- end}}
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (t {{.Type}}) Get(key string) {{.ValueType}} {
+	value, _ := t.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func ({{.Type}}) TryGet(key string) ({{.ValueType}}, bool) {
+{{- if .Entries}}
+	hash := hasher.Sum64String(key, {{.Prefix}}Seed)
+	bucket := (hash >> 32) * uint64(len({{.Prefix}}Displacements)) >> 32
+	mixed := hash + uint64({{.Prefix}}Displacements[bucket])*0x9e3779b97f4a7c15
+	mixed ^= mixed >> 33
+	mixed *= 0xff51afd7ed558ccd
+	mixed ^= mixed >> 33
+	mixed *= 0xc4ceb9fe1a85ec53
+	mixed ^= mixed >> 33
+	entry := &{{.Prefix}}Entries[uint64(uint32(mixed))*uint64(len({{.Prefix}}Entries))>>32]
+	if entry.key == key {
+		return entry.value, true
+	}
+{{- end}}
+	var zeroEntry {{.ValueType}}
+	return zeroEntry, false
+}
+
+// Get the number of entries stored in the table.
+func ({{.Type}}) Len() int {
+	return len({{.Prefix}}Entries)
+}
+`))
+
+// Template parameters
+type fileData struct {
+	Source        string
+	Package       string
+	Name          string
+	Type          string
+	Prefix        string
+	ValueType     string
+	Seed          uint64
+	Displacements []uint32
+	Entries       []templateEntry
+}
+
+// Template entry, both fields are Go source code
+type templateEntry struct {
+	Key   string
+	Value string
+}
+
+// Generate the source code of a static lookup table.
+func generate(t table, source, packageName, name string) ([]byte, error) {
+	entries := slices.Clone(t.entries)
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.key, b.key)
+	})
+	for i := 1; i < len(entries); i++ {
+		if entries[i].key == entries[i-1].key {
+			return nil, fmt.Errorf("duplicate key %q", entries[i].key)
+		}
+	}
+
+	// Seeds are tried in order, so the output is reproducible
+	hashes := make([]uint64, len(entries))
+	for seed := range uint64(maxSeeds) {
+		for i, entry := range entries {
+			hashes[i] = hasher.Sum64String(entry.key, seed)
+		}
+		displacements, ok := mph.Build(hashes)
+		if !ok {
+			continue
+		}
+
+		prefix := lowerFirst(name)
+		data := fileData{
+			Source:        source,
+			Package:       packageName,
+			Name:          name,
+			Type:          prefix + "Table",
+			Prefix:        prefix,
+			ValueType:     t.valueType,
+			Seed:          seed,
+			Displacements: displacements,
+			Entries:       make([]templateEntry, len(entries)),
+		}
+		for i, entry := range entries {
+			data.Entries[mph.Index(hashes[i], displacements, len(entries))] = templateEntry{
+				Key:   strconv.Quote(entry.key),
+				Value: entry.value,
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := fileTemplate.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		return format.Source(buf.Bytes())
+	}
+	return nil, fmt.Errorf("unable to build a perfect hash function after %d seeds", maxSeeds)
+}
+
+// Lower the first letter of an identifier.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
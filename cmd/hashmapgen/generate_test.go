@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadInputs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		varName   string
+		valueType string
+		expected  table
+	}{
+		{
+			name:      "table.json",
+			content:   `{"ok": 200}`,
+			valueType: "int",
+			expected:  table{"int", []entry{{"ok", "200"}}},
+		},
+		{
+			name:      "table.csv",
+			content:   "# comment\nhtml,text/html\n",
+			valueType: "string",
+			expected:  table{"string", []entry{{"html", `"text/html"`}}},
+		},
+		{
+			name:     "table.go",
+			content:  "package p\n\nvar other = 1\n\nvar kw = map[string]kind{\n\t\"func\": kind(1),\n}\n",
+			varName:  "kw",
+			expected: table{"kind", []entry{{"func", "kind(1)"}}},
+		},
+	}
+	for _, tc := range testCases {
+		path := filepath.Join(t.TempDir(), tc.name)
+		if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+			t.Fatalf("file creation failed: %v", err)
+		}
+
+		result, err := readInput(path, tc.varName, tc.valueType)
+		if err != nil {
+			t.Errorf("read failed for %s: %v", tc.name, err)
+			continue
+		}
+		if result.valueType != tc.expected.valueType || len(result.entries) != len(tc.expected.entries) || result.entries[0] != tc.expected.entries[0] {
+			t.Errorf("invalid table for %s. expected=%v, got=%v", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestReadInputErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		valueType string
+	}{
+		{"table.json", `{"ok": "not a number"}`, "int"},
+		{"table.json", `{"ok": [1]}`, "string"},
+		{"table.csv", "a,b,c\n", "string"},
+		{"table.go", "package p\n\nvar kw = map[int]int{1: 1}\n", ""},
+		{"table.txt", "", "string"},
+		{"table.json", `{"ok": 300}`, "int8"},
+		{"table.json", `{"ok": 70000}`, "uint16"},
+		{"table.json", `{"ok": 1e39}`, "float32"},
+		{"table.csv", "ok,NaN\n", "float64"},
+		{"table.csv", "ok,Inf\n", "float64"},
+		{"table.csv", "ok,-infinity\n", "float32"},
+	}
+	for _, tc := range testCases {
+		path := filepath.Join(t.TempDir(), tc.name)
+		if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+			t.Fatalf("file creation failed: %v", err)
+		}
+		if _, err := readInput(path, "kw", tc.valueType); err == nil {
+			t.Errorf("expected an error for %s with content=%q", tc.name, tc.content)
+		}
+	}
+}
+
+func TestGenerateDuplicateKeys(t *testing.T) {
+	if _, err := generate(table{"int", []entry{{"a", "1"}, {"a", "2"}}}, "", "p", "T"); err == nil {
+		t.Errorf("expected an error for duplicate keys")
+	}
+}
+
+// Compile and run generated tables.
+func TestGeneratedCode(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compilation of generated code in short mode")
+	}
+	_, currentFile, _, _ := runtime.Caller(0)
+	moduleRoot := filepath.Join(filepath.Dir(currentFile), "..", "..")
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module gentest\n\ngo 1.22\n\nrequire github.com/valsov/hashmap v0.0.0\n\nreplace github.com/valsov/hashmap => " + moduleRoot + "\n",
+		"main.go": `package main
+
+import "fmt"
+
+type kind int
+
+var keywords = map[string]kind{
+	"func": 1,
+	"var":  kind(2),
+	"if":   3,
+}
+
+func main() {
+	for key, value := range keywords {
+		if got, found := Keywords.TryGet(key); !found || got != value {
+			panic(fmt.Sprintf("invalid keyword %s: %v", key, got))
+		}
+	}
+	if _, found := Keywords.TryGet("else"); found {
+		panic("else was found")
+	}
+	if StatusCodes.Get("teapot") != 418 || StatusCodes.Len() != 3 {
+		panic("invalid status codes")
+	}
+	if _, found := Empty.TryGet("key"); found || Empty.Len() != 0 {
+		panic("invalid empty table")
+	}
+	fmt.Print("ok")
+}
+`,
+		"codes.csv":  "ok,200\nnotfound,404\nteapot,418\n",
+		"empty.json": "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("file creation failed: %v", err)
+		}
+	}
+
+	generateFile(t, dir, "main.go", "keywords", "", "Keywords")
+	generateFile(t, dir, "codes.csv", "", "int", "StatusCodes")
+	generateFile(t, dir, "empty.json", "", "string", "Empty")
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil || strings.TrimSpace(string(output)) != "ok" {
+		t.Errorf("generated code failed: %v\n%s", err, output)
+	}
+}
+
+func generateFile(t *testing.T, dir, input, varName, valueType, name string) {
+	t.Helper()
+	result, err := readInput(filepath.Join(dir, input), varName, valueType)
+	if err != nil {
+		t.Fatalf("read failed for %s: %v", input, err)
+	}
+	source, err := generate(result, input, "main", name)
+	if err != nil {
+		t.Fatalf("generation failed for %s: %v", input, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, strings.ToLower(name)+"_table.go"), source, 0o644); err != nil {
+		t.Fatalf("file creation failed: %v", err)
+	}
+}
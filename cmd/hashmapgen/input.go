@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Table entry, the value is Go source code
+type entry struct {
+	key   string
+	value string
+}
+
+// Parsed input file
+type table struct {
+	valueType string
+	entries   []entry
+}
+
+// Read the input file, its format is deduced from its extension.
+func readInput(path, varName, valueType string) (table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return table{}, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".go":
+		return readGoSource(data, varName)
+	case ".json":
+		return readJSON(data, valueType)
+	case ".csv":
+		return readCSV(data, valueType)
+	default:
+		return table{}, fmt.Errorf("unsupported input file extension: %s", filepath.Ext(path))
+	}
+}
+
+// Read a map[string]V literal assigned to the given package level variable.
+func readGoSource(data []byte, varName string) (table, error) {
+	if varName == "" {
+		return table{}, errors.New("-var is required for Go source input")
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", data, 0)
+	if err != nil {
+		return table{}, err
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, name := range valueSpec.Names {
+				if name.Name == varName && i < len(valueSpec.Values) {
+					return readMapLiteral(fset, valueSpec.Values[i])
+				}
+			}
+		}
+	}
+	return table{}, fmt.Errorf("variable %s not found", varName)
+}
+
+// Read the entries of a map[string]V composite literal.
+func readMapLiteral(fset *token.FileSet, expr ast.Expr) (table, error) {
+	literal, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return table{}, errors.New("variable is not initialized with a map literal")
+	}
+	mapType, ok := literal.Type.(*ast.MapType)
+	if !ok {
+		return table{}, errors.New("variable is not initialized with a map literal")
+	}
+	if keyType, ok := mapType.Key.(*ast.Ident); !ok || keyType.Name != "string" {
+		return table{}, errors.New("map keys must be of type string")
+	}
+
+	valueType, err := nodeSource(fset, mapType.Value)
+	if err != nil {
+		return table{}, err
+	}
+	result := table{valueType: valueType}
+	for _, element := range literal.Elts {
+		kv, ok := element.(*ast.KeyValueExpr)
+		if !ok {
+			return table{}, errors.New("invalid map literal element")
+		}
+		keyLiteral, ok := kv.Key.(*ast.BasicLit)
+		if !ok || keyLiteral.Kind != token.STRING {
+			return table{}, fmt.Errorf("map key must be a string literal at %s", fset.Position(kv.Key.Pos()))
+		}
+		key, err := strconv.Unquote(keyLiteral.Value)
+		if err != nil {
+			return table{}, err
+		}
+		value, err := nodeSource(fset, kv.Value)
+		if err != nil {
+			return table{}, err
+		}
+		result.entries = append(result.entries, entry{key, value})
+	}
+	return result, nil
+}
+
+// Read a JSON object, values are converted to the given type.
+func readJSON(data []byte, valueType string) (table, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return table{}, err
+	}
+
+	result := table{valueType: valueType}
+	for key, rawValue := range object {
+		var text string
+		switch value := rawValue.(type) {
+		case string:
+			text = value
+		case json.Number:
+			text = value.String()
+		case bool:
+			text = strconv.FormatBool(value)
+		default:
+			return table{}, fmt.Errorf("unsupported value for key %q, values must be strings, numbers or booleans", key)
+		}
+		value, err := valueLiteral(text, valueType)
+		if err != nil {
+			return table{}, fmt.Errorf("invalid value for key %q: %w", key, err)
+		}
+		result.entries = append(result.entries, entry{key, value})
+	}
+	return result, nil
+}
+
+// Read key,value rows, values are converted to the given type. Lines starting with # are ignored.
+func readCSV(data []byte, valueType string) (table, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = 2
+	reader.Comment = '#'
+
+	result := table{valueType: valueType}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return table{}, err
+		}
+		value, err := valueLiteral(record[1], valueType)
+		if err != nil {
+			return table{}, fmt.Errorf("invalid value for key %q: %w", record[0], err)
+		}
+		result.entries = append(result.entries, entry{record[0], value})
+	}
+}
+
+// Convert a value read from a JSON or CSV file to a Go literal of the given type.
+func valueLiteral(text, valueType string) (string, error) {
+	switch valueType {
+	case "string":
+		return strconv.Quote(text), nil
+	case "int", "int8", "int16", "int32", "int64":
+		if _, err := strconv.ParseInt(text, 10, bitSize(valueType)); err != nil {
+			return "", err
+		}
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		if _, err := strconv.ParseUint(text, 10, bitSize(valueType)); err != nil {
+			return "", err
+		}
+	case "float32", "float64":
+		value, err := strconv.ParseFloat(text, bitSize(valueType))
+		if err != nil {
+			return "", err
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			// No Go literal for them
+			return "", fmt.Errorf("non-finite %s value: %s", valueType, text)
+		}
+	case "bool":
+		if _, err := strconv.ParseBool(text); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported value type: %s", valueType)
+	}
+	return text, nil
+}
+
+// Get the size in bits of a numeric type, 0 for int and uint whose size depends on the platform.
+func bitSize(numericType string) int {
+	size, _ := strconv.Atoi(strings.TrimLeft(numericType, "abcdefghijklmnopqrstuvwxyz"))
+	return size
+}
+
+// Print the source code of a node.
+func nodeSource(fset *token.FileSet, node ast.Node) (string, error) {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
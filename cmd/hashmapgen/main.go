@@ -0,0 +1,64 @@
+// Command hashmapgen generates static lookup tables from key/value files, to be used with go generate.
+//
+// The table is built at generation time with a minimal perfect hash function based on the stable hasher
+// of the hasher package: the generated file only contains constant data, and lookups don't need any initialization.
+//
+// Supported inputs, the format is deduced from the file extension:
+//   - .go: a package level map[string]V variable initialized with a literal, selected with -var
+//   - .json: an object, its values are converted to the -type type
+//   - .csv: key,value rows, values are converted to the -type type
+//
+// Usage:
+//
+//	//go:generate hashmapgen -input mime.json -name MimeTypes
+//
+// This generates a MimeTypes variable, with Get, TryGet and Len methods.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	input := flag.String("input", "", "input file (.go, .json or .csv)")
+	output := flag.String("output", "", "output file (default: <name>_table.go, lowercase)")
+	name := flag.String("name", "", "name of the generated table variable")
+	packageName := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file (default: $GOPACKAGE)")
+	varName := flag.String("var", "", "map variable to read, for Go source input")
+	valueType := flag.String("type", "string", "value type, for JSON and CSV input")
+	flag.Parse()
+
+	if *input == "" || *name == "" || *packageName == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !token.IsIdentifier(*name) {
+		fail(fmt.Errorf("invalid table name: %s", *name))
+	}
+	if *output == "" {
+		*output = strings.ToLower(*name) + "_table.go"
+	}
+
+	t, err := readInput(*input, *varName, *valueType)
+	if err != nil {
+		fail(err)
+	}
+	source, err := generate(t, filepath.Base(*input), *packageName, *name)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+// Print the error and exit.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "hashmapgen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,226 @@
+package hashmap
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// In-flight load of a ConcurrentHashmap key, shared by all callers of GetOrCompute.
+type computeCall[TValue any] struct {
+	done    chan struct{} // Closed once value and err are set
+	value   TValue
+	err     error
+	waiters int // Callers waiting for the result, guarded by the compute mutex
+	cancel  context.CancelFunc
+	deleted bool // The key was deleted or the hashmap cleared during the load, guarded by the compute mutex
+}
+
+// Error returned by GetOrCompute when the loader panicked.
+type LoaderPanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack of the loader goroutine when it panicked
+}
+
+func (e *LoaderPanicError) Error() string {
+	return fmt.Sprintf("hashmap: GetOrCompute loader panicked: %v", e.Value)
+}
+
+// State of GetOrCompute, the zero value is ready to use.
+type computeState[TKey comparable, TValue any] struct {
+	mu            sync.Mutex
+	calls         Hashmap[TKey, *computeCall[TValue]]
+	failures      Hashmap[TKey, error] // Cached errors
+	failuresCount atomic.Int64         // Allows writes to skip the mutex when no error is cached
+	callsCount    atomic.Int64         // Allows deletions to skip the mutex when no load is in flight
+}
+
+// Options of ConcurrentHashmap.GetOrCompute.
+type ComputeOption func(*computeOptions)
+
+type computeOptions struct {
+	cacheErrors bool
+}
+
+// Cache errors returned by the loader: later calls for the same key return the error without loading it again,
+// until the key is set or deleted. Errors are not cached when the loader is canceled.
+func WithErrorCaching() ComputeOption {
+	return func(options *computeOptions) {
+		options.cacheErrors = true
+	}
+}
+
+// Get the value associated with the given key, or load it if it doesn't exist.
+//
+// Concurrent calls for the same key share a single load, and all get its result or error. The loader runs in its own
+// goroutine: a caller whose context is done stops waiting and gets the context error, and the loader context is
+// canceled once all callers stopped waiting. A panic of the loader is returned as a *LoaderPanicError.
+//
+// Loaded values are only stored if the key is still missing: if it was set during the load, callers get the set value.
+// If it was deleted during the load, the loaded value is not stored, and callers get it unless the key was set again. Errors are not cached by default.
+func (m *ConcurrentHashmap[TKey, TValue]) GetOrCompute(ctx context.Context, key TKey, loader func(ctx context.Context) (TValue, error), options ...ComputeOption) (TValue, error) {
+	if value, found := m.TryGet(key); found {
+		return value, nil
+	}
+
+	var computeOptions computeOptions
+	for _, option := range options {
+		option(&computeOptions)
+	}
+
+	state := &m.compute
+	state.mu.Lock()
+	value, found, expired := m.lookup(key)
+	if found {
+		// Loaded meanwhile
+		state.mu.Unlock()
+		return value, nil
+	}
+	if err, found := state.failures.TryGet(key); found {
+		state.mu.Unlock()
+		m.publishExpired(expired)
+		var zeroEntry TValue
+		return zeroEntry, err
+	}
+	call, found := state.calls.TryGet(key)
+	if !found {
+		loadCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &computeCall[TValue]{done: make(chan struct{}), cancel: cancel}
+		state.calls.Set(key, call)
+		state.callsCount.Store(int64(state.calls.Len()))
+		go m.load(loadCtx, key, call, loader, computeOptions)
+	}
+	call.waiters++
+	state.mu.Unlock()
+	// Outside of the lock, watchers may block writers
+	m.publishExpired(expired)
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		state.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody waits for the result anymore, later calls start a new load
+			call.cancel()
+			if current, _ := state.calls.TryGet(key); current == call {
+				state.calls.Delete(key)
+				state.callsCount.Store(int64(state.calls.Len()))
+			}
+		}
+		state.mu.Unlock()
+		var zeroEntry TValue
+		return zeroEntry, ctx.Err()
+	}
+}
+
+// Run the loader of a call, store its result and wake up waiters.
+func (m *ConcurrentHashmap[TKey, TValue]) load(ctx context.Context, key TKey, call *computeCall[TValue], loader func(ctx context.Context) (TValue, error), options computeOptions) {
+	value, err := runLoader(ctx, loader)
+	defer call.cancel()
+
+	state := &m.compute
+	state.mu.Lock()
+	stored := false
+	var expired *concurrentWord[TKey, TValue]
+	switch {
+	case err != nil:
+		if options.cacheErrors && ctx.Err() == nil {
+			state.failures.Set(key, err)
+			state.failuresCount.Store(int64(state.failures.Len()))
+		}
+	case call.deleted:
+		var current TValue
+		var found bool
+		if current, found, expired = m.lookup(key); found {
+			// Set again after the deletion
+			value = current
+		}
+	default:
+		var current TValue
+		var existed bool
+		if current, existed, expired = m.store(key, value, 0, false, true); existed {
+			// Set during the load, the set value is kept
+			value = current
+		} else {
+			stored = true
+			m.wake(key, value)
+		}
+	}
+	if current, _ := state.calls.TryGet(key); current == call {
+		state.calls.Delete(key)
+		state.callsCount.Store(int64(state.calls.Len()))
+	}
+	call.value, call.err = value, err
+	close(call.done)
+	state.mu.Unlock()
+
+	// Outside of the lock, watchers may block writers
+	m.publishExpired(expired)
+	if stored {
+		m.publish(Event[TKey, TValue]{Kind: EventSet, Key: key, NewValue: value})
+	}
+}
+
+// Call the loader, recovering its panic.
+func runLoader[TValue any](ctx context.Context, loader func(ctx context.Context) (TValue, error)) (value TValue, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = &LoaderPanicError{Value: recovered, Stack: debug.Stack()}
+		}
+	}()
+	return loader(ctx)
+}
+
+// Keep the in-flight load of the given key, if any, from storing its value. Must be called before deleting the key.
+func (m *ConcurrentHashmap[TKey, TValue]) invalidateLoad(key TKey) {
+	state := &m.compute
+	if state.callsCount.Load() == 0 {
+		return
+	}
+	state.mu.Lock()
+	if call, found := state.calls.TryGet(key); found {
+		call.deleted = true
+	}
+	state.mu.Unlock()
+}
+
+// Keep all in-flight loads from storing their value. Must be called before clearing the hashmap.
+func (m *ConcurrentHashmap[TKey, TValue]) invalidateLoads() {
+	state := &m.compute
+	if state.callsCount.Load() == 0 {
+		return
+	}
+	state.mu.Lock()
+	for _, entry := range state.calls.GetEntries() {
+		entry.Value.deleted = true
+	}
+	state.mu.Unlock()
+}
+
+// Drop all cached errors.
+func (m *ConcurrentHashmap[TKey, TValue]) forgetFailures() {
+	state := &m.compute
+	if state.failuresCount.Load() == 0 {
+		return
+	}
+	state.mu.Lock()
+	state.failures.Clear()
+	state.failuresCount.Store(0)
+	state.mu.Unlock()
+}
+
+// Drop the cached error of the given key, if any.
+func (m *ConcurrentHashmap[TKey, TValue]) forgetFailure(key TKey) {
+	state := &m.compute
+	if state.failuresCount.Load() == 0 {
+		return
+	}
+	state.mu.Lock()
+	state.failures.Delete(key)
+	state.failuresCount.Store(int64(state.failures.Len()))
+	state.mu.Unlock()
+}
@@ -0,0 +1,238 @@
+package hashmap
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Wait until the in-flight load of the key has the given number of waiters.
+func waitForWaiters[TKey comparable, TValue any](m *ConcurrentHashmap[TKey, TValue], key TKey, waiters int) {
+	for {
+		m.compute.mu.Lock()
+		call, found := m.compute.calls.TryGet(key)
+		current := 0
+		if found {
+			current = call.waiters
+		}
+		m.compute.mu.Unlock()
+		if current == waiters {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGetOrComputeSingleflight(t *testing.T) {
+	const goroutines = 100
+	m := NewConcurrent[string, int]()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	loader := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := m.GetOrCompute(context.Background(), "key", loader)
+			if value != 42 || err != nil {
+				t.Errorf("invalid result. expected=(42, nil), got=(%d, %v)", value, err)
+			}
+		}()
+	}
+	waitForWaiters(m, "key", goroutines)
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Errorf("invalid loads count. expected=1, got=%d", loads.Load())
+	}
+	if value := m.Get("key"); value != 42 {
+		t.Errorf("loaded value not stored. expected=42, got=%d", value)
+	}
+	value, err := m.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+		t.Error("existing key loaded")
+		return 0, nil
+	})
+	if value != 42 || err != nil {
+		t.Errorf("invalid result. expected=(42, nil), got=(%d, %v)", value, err)
+	}
+}
+
+func TestGetOrComputeErrors(t *testing.T) {
+	errLoad := errors.New("load failed")
+	var loads atomic.Int32
+	loader := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		return 0, errLoad
+	}
+
+	m := NewConcurrent[string, int]()
+	for range 2 {
+		if _, err := m.GetOrCompute(context.Background(), "key", loader); err != errLoad {
+			t.Errorf("invalid error. expected=%v, got=%v", errLoad, err)
+		}
+	}
+	if loads.Load() != 2 {
+		t.Errorf("errors must not be cached by default. expected=2 loads, got=%d", loads.Load())
+	}
+	if _, found := m.TryGet("key"); found {
+		t.Error("failed load stored")
+	}
+
+	loads.Store(0)
+	for range 2 {
+		if _, err := m.GetOrCompute(context.Background(), "cached", loader, WithErrorCaching()); err != errLoad {
+			t.Errorf("invalid error. expected=%v, got=%v", errLoad, err)
+		}
+	}
+	if loads.Load() != 1 {
+		t.Errorf("invalid loads count with error caching. expected=1, got=%d", loads.Load())
+	}
+	m.Set("cached", 1)
+	m.Delete("cached")
+	if _, err := m.GetOrCompute(context.Background(), "cached", loader, WithErrorCaching()); err != errLoad || loads.Load() != 2 {
+		t.Errorf("cached error not dropped by writes. loads=%d, err=%v", loads.Load(), err)
+	}
+}
+
+func TestGetOrComputeCancellation(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	loaderCanceled := make(chan struct{})
+	loader := func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(loaderCanceled)
+		return 0, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	go func() {
+		_, err := m.GetOrCompute(ctx1, "key", loader)
+		errs <- err
+	}()
+	go func() {
+		_, err := m.GetOrCompute(ctx2, "key", loader)
+		errs <- err
+	}()
+	waitForWaiters(m, "key", 2)
+
+	cancel1()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("invalid error of the canceled waiter. expected=%v, got=%v", context.Canceled, err)
+	}
+	select {
+	case <-loaderCanceled:
+		t.Fatal("loader canceled while a caller is still waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel2()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("invalid error of the canceled waiter. expected=%v, got=%v", context.Canceled, err)
+	}
+	select {
+	case <-loaderCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("loader not canceled once all callers stopped waiting")
+	}
+}
+
+// Writes made during a load must not be overwritten by the loaded value.
+func TestGetOrComputeConcurrentWrites(t *testing.T) {
+	cases := []struct {
+		name          string
+		write         func(m *ConcurrentHashmap[string, int])
+		expectedValue int
+		expectedFound bool
+	}{
+		{"Set", func(m *ConcurrentHashmap[string, int]) { m.Set("key", 99) }, 99, true},
+		{"SetDelete", func(m *ConcurrentHashmap[string, int]) { m.Set("key", 99); m.Delete("key") }, 42, false},
+		{"DeleteSet", func(m *ConcurrentHashmap[string, int]) { m.Delete("key"); m.Set("key", 99) }, 99, true},
+		{"Clear", func(m *ConcurrentHashmap[string, int]) { m.Clear() }, 42, false},
+		{"Expired", func(m *ConcurrentHashmap[string, int]) { m.SetWithTTL("key", 99, -time.Second) }, 42, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := NewConcurrent[string, int]()
+			release := make(chan struct{})
+			result := make(chan int)
+			go func() {
+				value, _ := m.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+					<-release
+					return 42, nil
+				})
+				result <- value
+			}()
+			waitForWaiters(m, "key", 1)
+			c.write(m)
+			close(release)
+
+			if value := <-result; value != c.expectedValue {
+				t.Errorf("invalid returned value. expected=%d, got=%d", c.expectedValue, value)
+			}
+			if value, found := m.TryGet("key"); found != c.expectedFound || found && value != c.expectedValue {
+				t.Errorf("write overwritten by the load. got=(%d, %t)", value, found)
+			}
+		})
+	}
+}
+
+func TestGetOrComputePanic(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	_, err := m.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("loader failure")
+	})
+	var panicErr *LoaderPanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "loader failure" || len(panicErr.Stack) == 0 {
+		t.Fatalf("invalid error. expected a loader panic error, got=%v", err)
+	}
+	if _, found := m.TryGet("key"); found {
+		t.Error("value stored after a loader panic")
+	}
+	if value, err := m.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) { return 1, nil }); value != 1 || err != nil {
+		t.Errorf("invalid result after a loader panic. expected=(1, nil), got=(%d, %v)", value, err)
+	}
+}
+
+// A watcher blocking writers can write to the hashmap: loads must not publish expirations while holding the compute lock.
+func TestGetOrComputeBlockingWatcher(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	events, cancel := m.Watch(nil, WithWatchBuffer(0), WithWatchPolicy(BlockWriters))
+	defer cancel()
+	go func() {
+		for event := range events {
+			if event.Kind == EventSet {
+				time.Sleep(10 * time.Millisecond) // Let the load take the compute lock
+				m.Delete("other")                 // Takes the compute lock, a load is in flight
+			}
+		}
+	}()
+
+	result := make(chan int)
+	go func() {
+		value, _ := m.GetOrCompute(context.Background(), "key", func(ctx context.Context) (int, error) {
+			m.Delete("key")
+			m.SetWithTTL("key", 1, -time.Second) // Found expired by the load
+			return 42, nil
+		})
+		result <- value
+	}()
+	select {
+	case value := <-result:
+		if value != 42 {
+			t.Errorf("invalid returned value. expected=42, got=%d", value)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock between the load and the watcher")
+	}
+}
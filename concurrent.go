@@ -0,0 +1,616 @@
+package hashmap
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+const concurrentShardSize = 16 // Number of slots sharing a stamp
+
+// Open addressing table with Robin Hood hashing, whose slots atomically point to entries.
+//
+// Slots are grouped in shards, each one having a stamp which changes whenever one of its slots is written.
+// Writes change all their slots and stamps at once with a multi-word CAS, which also checks that the stamps of the
+// shards they only read are unchanged. Reads check that the stamps of the shards they probed are unchanged.
+type concurrentTable[TKey comparable, TValue any] struct {
+	slots      []atomic.Pointer[concurrentWord[TKey, TValue]]
+	stamps     []atomic.Pointer[concurrentWord[TKey, TValue]]
+	mask       uint64
+	maxLength  int64
+	generation uint64 // Incremented by resizes, orders the words of successive tables
+	cleared    bool   // The entries of the previous table are dropped instead of copied, see Clear
+	next       atomic.Pointer[concurrentTable[TKey, TValue]]
+	copyIndex  atomic.Int64 // Next shard to copy
+}
+
+// Reads and writes of an operation on a table, which is applied with a multi-word CAS or validated.
+type concurrentOp[TKey comparable, TValue any] struct {
+	table    *concurrentTable[TKey, TValue]
+	shards   []int                           // Shards read, in reading order
+	stamps   []*concurrentWord[TKey, TValue] // Stamps of the shards read, read before their slots
+	modified []bool                          // Whether the shards read are written
+	writes   []kcasEntry[TKey, TValue]
+	pending  map[int]int // Position in writes of the slots written, only tracked by operations writing several keys
+	added    int64       // Number of entries added by the writes, negative if entries are removed
+	frozen   bool        // A shard read is frozen, the table is being resized
+	now      int64       // Time of the operation, read once an entry with a deadline is found
+}
+
+// Lock-free hashmap, safe for concurrent use.
+//
+// Entries are placed with Robin Hood hashing, deletions shift the following entries backward: no tombstone is left.
+// Both move several entries at once, which is done atomically with a lock-free multi-word CAS, see kcas.
+// Get, TryGet, Set, Delete, Clear and GetEntries are linearizable, Len is not when called concurrently with writes.
+//
+// Resizes are cooperative: the next table is published and every shard of the table is frozen, then operations
+// copy shards before proceeding, until the next table replaces the table. Clear uses a resize which copies nothing.
+//
+// Entries set with SetWithTTL expire: they are hidden once their deadline passed, and removed by the next read or write
+// of their key, or by DeleteExpired. Len counts expired entries until they are removed.
+type ConcurrentHashmap[TKey comparable, TValue any] struct {
+	table      atomic.Pointer[concurrentTable[TKey, TValue]]
+	length     atomic.Int64
+	loadFactor float32
+	hashFunc   func(uintptr, uintptr) uintptr
+	hashSeed   uintptr
+	compute    computeState[TKey, TValue] // See GetOrCompute
+	wait       waitState[TKey, TValue]    // See WaitFor
+	watch      watchState[TKey, TValue]   // See Watch
+}
+
+// Instanciate a new concurrent hashmap.
+//
+// The initial capacity, load percentage, hash function and seed configurations are supported, other ones are ignored.
+func NewConcurrent[TKey comparable, TValue any](config ...HashMapConfig[TKey, TValue]) *ConcurrentHashmap[TKey, TValue] {
+	options := Hashmap[TKey, TValue]{
+		initialCapacity: defaultInitialCapacity,
+		loadFactor:      defaultLoadFactor,
+		hashSeed:        hasher.GenerateSeed(),
+	}
+	for _, configFunc := range config {
+		configFunc(&options)
+	}
+	if options.hashFunc == nil {
+		options.hashFunc = hasher.GetHashFunc[TKey]()
+	}
+
+	m := &ConcurrentHashmap[TKey, TValue]{
+		loadFactor: options.loadFactor,
+		hashFunc:   options.hashFunc,
+		hashSeed:   options.hashSeed,
+	}
+	slots := 2
+	for slots < int(options.initialCapacity) {
+		slots *= 2
+	}
+	m.table.Store(m.newTable(slots, 0))
+	return m
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *ConcurrentHashmap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+//
+// An expired entry found is removed, and its expiration published.
+func (m *ConcurrentHashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	value, found, expired := m.lookup(key)
+	m.publishExpired(expired)
+	return value, found
+}
+
+// Get the value associated with the given key. An expired entry found is removed and returned as the third result,
+// its expiration must be published by the caller, once it doesn't hold any lock.
+func (m *ConcurrentHashmap[TKey, TValue]) lookup(key TKey) (TValue, bool, *concurrentWord[TKey, TValue]) {
+	entry, expired := m.find(key, m.hash(key))
+	if entry == nil {
+		var zeroEntry TValue
+		return zeroEntry, false, expired
+	}
+	return entry.value, true, nil
+}
+
+// Get the entry of the given key, nil if there is none. An expired entry found is removed and returned as the second
+// result, its expiration must be published by the caller.
+func (m *ConcurrentHashmap[TKey, TValue]) find(key TKey, hash uint64) (*concurrentWord[TKey, TValue], *concurrentWord[TKey, TValue]) {
+	for {
+		op := &concurrentOp[TKey, TValue]{table: m.table.Load()}
+		_, _, entry := op.probe(key, hash)
+		if op.validate() {
+			if entry == nil || entry.hash != hash || entry.key != key {
+				return nil, nil
+			}
+			if op.expired(entry) {
+				return nil, m.expire(key, hash, entry)
+			}
+			return entry, nil
+		}
+	}
+}
+
+// Insert or update the given value at the given key.
+func (m *ConcurrentHashmap[TKey, TValue]) Set(key TKey, value TValue) {
+	m.set(key, value, 0)
+}
+
+// Insert or update the given value at the given key, the entry expires once the given duration elapsed.
+func (m *ConcurrentHashmap[TKey, TValue]) SetWithTTL(key TKey, value TValue, ttl time.Duration) {
+	m.set(key, value, time.Now().Add(ttl).UnixNano())
+}
+
+func (m *ConcurrentHashmap[TKey, TValue]) set(key TKey, value TValue, deadline int64) {
+	old, existed, expired := m.store(key, value, deadline, false, false)
+	m.forgetFailure(key)
+	m.wake(key, value)
+	m.publishExpired(expired)
+	m.publish(Event[TKey, TValue]{Kind: EventSet, Key: key, OldValue: old, Existed: existed, NewValue: value})
+}
+
+// Remove the entry with the given key from the hashmap.
+func (m *ConcurrentHashmap[TKey, TValue]) Delete(key TKey) {
+	m.invalidateLoad(key)
+	var zeroEntry TValue
+	old, existed, expired := m.store(key, zeroEntry, 0, true, false)
+	m.forgetFailure(key)
+	m.publishExpired(expired)
+	if existed {
+		m.publish(Event[TKey, TValue]{Kind: EventDelete, Key: key, OldValue: old, Existed: true})
+	}
+}
+
+// Remove all entries from the hashmap.
+//
+// The table is replaced by an empty one of the same capacity, with a resize whose shards are frozen but not copied.
+func (m *ConcurrentHashmap[TKey, TValue]) Clear() {
+	m.invalidateLoads()
+	for {
+		t := m.table.Load()
+		if t.next.Load() != nil {
+			m.resize(t)
+			continue
+		}
+
+		next := m.newTable(len(t.slots), t.generation+1)
+		next.cleared = true
+		if t.next.CompareAndSwap(nil, next) {
+			m.resize(t)
+			// The table is frozen, its entries can be counted
+			for i := range t.slots {
+				if readWord(&t.slots[i]) != nil {
+					m.length.Add(-1)
+				}
+			}
+			break
+		}
+	}
+	m.forgetFailures()
+	m.publish(Event[TKey, TValue]{Kind: EventClear})
+}
+
+// Remove the expired entries, returns the number of removed entries.
+func (m *ConcurrentHashmap[TKey, TValue]) DeleteExpired() int {
+	removed := 0
+	t := m.table.Load()
+	op := &concurrentOp[TKey, TValue]{table: t}
+	for i := 0; i < len(t.slots); i++ {
+		// Entries moved by concurrent writes may be missed, they are removed by the next call
+		entry := readWord(&t.slots[i])
+		if entry == nil || !op.expired(entry) {
+			continue
+		}
+		if expired := m.expire(entry.key, entry.hash, entry); expired != nil {
+			m.publishExpired(expired)
+			removed++
+			i-- // The following entry may have been shifted to this slot
+		}
+	}
+	return removed
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *ConcurrentHashmap[TKey, TValue]) Len() int {
+	return int(m.length.Load())
+}
+
+// Get all entries stored in the hashmap. The slice ordering is not guaranteed.
+//
+// The entries are collected again until no shard changed meanwhile, writes can delay the collection but not break it.
+func (m *ConcurrentHashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	for {
+		t := m.table.Load()
+		op := &concurrentOp[TKey, TValue]{table: t}
+		entries := make([]KeyValue[TKey, TValue], 0, m.Len())
+		for i := range t.slots {
+			if entry := op.read(i); entry != nil && !op.expired(entry) {
+				entries = append(entries, KeyValue[TKey, TValue]{Key: entry.key, Value: entry.value})
+			}
+		}
+		if op.validate() {
+			return entries
+		}
+	}
+}
+
+// Insert, update or delete the given key, returns its previous value. An expired previous entry is not returned
+// but as the third result, so that its expiration is published.
+// With ifAbsent, an existing entry is kept: its value is returned and nothing is written.
+func (m *ConcurrentHashmap[TKey, TValue]) store(key TKey, value TValue, deadline int64, deleted, ifAbsent bool) (TValue, bool, *concurrentWord[TKey, TValue]) {
+	hash := m.hash(key)
+	for {
+		t := m.table.Load()
+		if t.next.Load() != nil {
+			m.resize(t)
+			continue
+		}
+
+		op := &concurrentOp[TKey, TValue]{table: t}
+		var previous *concurrentWord[TKey, TValue]
+		var done bool
+		if deleted {
+			previous, done = m.remove(op, key, hash, nil)
+		} else {
+			previous, done = m.insert(op, &concurrentWord[TKey, TValue]{key: key, hash: hash, value: value, deadline: deadline}, ifAbsent)
+		}
+		if done {
+			var zeroEntry TValue
+			switch {
+			case previous == nil:
+				return zeroEntry, false, nil
+			case op.expired(previous):
+				return zeroEntry, false, previous
+			default:
+				return previous.value, true, nil
+			}
+		}
+		if op.frozen {
+			m.resize(t)
+		}
+	}
+}
+
+// Remove the given expired entry, unless it was changed meanwhile. Returns the entry if it was removed,
+// its expiration must then be published by the caller.
+func (m *ConcurrentHashmap[TKey, TValue]) expire(key TKey, hash uint64, entry *concurrentWord[TKey, TValue]) *concurrentWord[TKey, TValue] {
+	for {
+		t := m.table.Load()
+		if t.next.Load() != nil {
+			m.resize(t)
+			continue
+		}
+
+		op := &concurrentOp[TKey, TValue]{table: t}
+		if previous, done := m.remove(op, key, hash, entry); done {
+			return previous
+		}
+		if op.frozen {
+			m.resize(t)
+		}
+	}
+}
+
+// Publish the expiration of an entry, if any.
+func (m *ConcurrentHashmap[TKey, TValue]) publishExpired(entry *concurrentWord[TKey, TValue]) {
+	if entry != nil {
+		m.publish(Event[TKey, TValue]{Kind: EventExpire, Key: entry.key, OldValue: entry.value, Existed: true})
+	}
+}
+
+// Insert or update the key of the entry in the table of the operation. Returns the previous entry of the key and
+// whether the operation was applied, it must be retried otherwise.
+func (m *ConcurrentHashmap[TKey, TValue]) insert(op *concurrentOp[TKey, TValue], entry *concurrentWord[TKey, TValue], ifAbsent bool) (*concurrentWord[TKey, TValue], bool) {
+	previous, recorded := m.recordInsert(op, entry, ifAbsent)
+	switch {
+	case !recorded:
+		return nil, false
+	case len(op.writes) == 0:
+		return previous, op.validate()
+	case !op.commit():
+		return nil, false
+	}
+	m.length.Add(op.added)
+	return previous, true
+}
+
+// Record the insertion or update of the key of the entry in the operation, with ifAbsent nothing is recorded if the key
+// exists. Returns the previous entry of the key and whether the operation can be committed, it must be retried otherwise.
+func (m *ConcurrentHashmap[TKey, TValue]) recordInsert(op *concurrentOp[TKey, TValue], entry *concurrentWord[TKey, TValue], ifAbsent bool) (*concurrentWord[TKey, TValue], bool) {
+	index, distance, current := op.probe(entry.key, entry.hash)
+	if op.frozen {
+		return nil, false
+	}
+
+	if current != nil && current.hash == entry.hash && current.key == entry.key {
+		if !ifAbsent || op.expired(current) {
+			op.write(index, current, entry)
+		}
+		return current, true
+	}
+
+	if index < 0 || m.length.Load()+op.added >= op.table.maxLength || !op.place(index, distance, entry) {
+		if !op.frozen {
+			m.grow(op.table)
+		}
+		return nil, false
+	}
+	op.added++
+	return nil, true
+}
+
+// Delete the key from the table of the operation, shifting the following entries of the cluster backward.
+// With only, the key is deleted only if it still holds this entry.
+// Returns the deleted entry and whether the operation was applied, it must be retried otherwise.
+func (m *ConcurrentHashmap[TKey, TValue]) remove(op *concurrentOp[TKey, TValue], key TKey, hash uint64, only *concurrentWord[TKey, TValue]) (*concurrentWord[TKey, TValue], bool) {
+	previous, recorded := m.recordRemove(op, key, hash, only)
+	switch {
+	case !recorded:
+		return nil, false
+	case previous == nil:
+		return nil, op.validate()
+	case !op.commit():
+		return nil, false
+	}
+	m.length.Add(op.added)
+	return previous, true
+}
+
+// Record the deletion of the key in the operation, with only nothing is recorded unless the key holds this entry.
+// Returns the deleted entry, nil if nothing is recorded, and whether the operation can be committed, it must be
+// retried otherwise.
+func (m *ConcurrentHashmap[TKey, TValue]) recordRemove(op *concurrentOp[TKey, TValue], key TKey, hash uint64, only *concurrentWord[TKey, TValue]) (*concurrentWord[TKey, TValue], bool) {
+	index, _, current := op.probe(key, hash)
+	if op.frozen {
+		return nil, false
+	}
+	if current == nil || current.hash != hash || current.key != key || only != nil && current != only {
+		return nil, true
+	}
+
+	t := op.table
+	hole, holeEntry := index, current
+	for range t.slots {
+		next := (hole + 1) & int(t.mask)
+		following := op.read(next)
+		if op.frozen {
+			return nil, false
+		}
+		if following == nil || t.distance(following, next) == 0 {
+			op.write(hole, holeEntry, nil)
+			op.added--
+			return current, true
+		}
+		op.write(hole, holeEntry, following)
+		hole, holeEntry = next, following
+	}
+	// Full table without any entry in its ideal slot, grow it first
+	m.grow(t)
+	return nil, false
+}
+
+// Start a resize of the given table if none is ongoing.
+func (m *ConcurrentHashmap[TKey, TValue]) grow(t *concurrentTable[TKey, TValue]) {
+	if t.next.Load() == nil {
+		t.next.CompareAndSwap(nil, m.newTable(len(t.slots)*2, t.generation+1))
+	}
+}
+
+// Help the resize of the given table, returns once the next table replaced it.
+//
+// All shards are frozen first, so that no write can change them anymore. Then shards are claimed and copied,
+// and finally all shards are copied again: this is idempotent, and ensures progress if a goroutine stalled while copying.
+func (m *ConcurrentHashmap[TKey, TValue]) resize(t *concurrentTable[TKey, TValue]) {
+	for shard := range t.stamps {
+		t.markShard(shard, false)
+	}
+	for {
+		shard := int(t.copyIndex.Add(1)) - 1
+		if shard >= len(t.stamps) {
+			break
+		}
+		m.copyShard(t, shard)
+	}
+	for shard := range t.stamps {
+		m.copyShard(t, shard)
+	}
+	m.table.CompareAndSwap(t, t.next.Load())
+}
+
+// Copy the entries of a frozen shard to the next table, unless it is already copied or the table is cleared.
+func (m *ConcurrentHashmap[TKey, TValue]) copyShard(t *concurrentTable[TKey, TValue], shard int) {
+	if t.next.Load().cleared {
+		t.markShard(shard, true)
+		return
+	}
+	for index := shard * concurrentShardSize; index < min((shard+1)*concurrentShardSize, len(t.slots)); index++ {
+		entry := readWord(&t.slots[index])
+		if entry == nil {
+			continue
+		}
+		for !m.copyEntry(t, shard, entry) {
+			// Another copy changed the next table meanwhile, probe again
+		}
+	}
+	t.markShard(shard, true)
+}
+
+// Insert an entry of a frozen shard in the next table, unless its key is already there.
+// Returns whether the entry is copied, it must be retried otherwise.
+//
+// The copy also checks that the shard isn't marked as copied: writers use the next table once every shard is copied,
+// so late copies would overwrite their changes. A key found in the next table was put there by another copy of the entry.
+// The next table is twice as large, copies can't fill it.
+func (m *ConcurrentHashmap[TKey, TValue]) copyEntry(t *concurrentTable[TKey, TValue], shard int, entry *concurrentWord[TKey, TValue]) bool {
+	stamp := readWord(&t.stamps[shard])
+	if stamp.copied {
+		return true
+	}
+
+	op := &concurrentOp[TKey, TValue]{table: t.next.Load()}
+	index, distance, current := op.probe(entry.key, entry.hash)
+	if current != nil && current.hash == entry.hash && current.key == entry.key {
+		return true
+	}
+	if index < 0 || !op.place(index, distance, entry) {
+		panic("hashmap: concurrent hashmap resize target is full")
+	}
+	op.writes = append(op.writes, kcasEntry[TKey, TValue]{word: &t.stamps[shard], order: t.stampOrder(shard), old: stamp, new: stamp})
+	return op.commit()
+}
+
+// Compute the hash of the given key.
+func (m *ConcurrentHashmap[TKey, TValue]) hash(key TKey) uint64 {
+	return uint64(m.hashFunc(uintptr(unsafe.Pointer(&key)), m.hashSeed))
+}
+
+// Create an empty table with the given number of slots, a power of 2.
+// At least one slot is kept empty whatever the load factor, so that probes end.
+func (m *ConcurrentHashmap[TKey, TValue]) newTable(slots int, generation uint64) *concurrentTable[TKey, TValue] {
+	t := &concurrentTable[TKey, TValue]{
+		slots:      make([]atomic.Pointer[concurrentWord[TKey, TValue]], slots),
+		stamps:     make([]atomic.Pointer[concurrentWord[TKey, TValue]], (slots+concurrentShardSize-1)/concurrentShardSize),
+		mask:       uint64(slots - 1),
+		maxLength:  min(int64(float64(slots)*float64(m.loadFactor)), int64(slots-1)),
+		generation: generation,
+	}
+	for i := range t.stamps {
+		t.stamps[i].Store(&concurrentWord[TKey, TValue]{})
+	}
+	return t
+}
+
+// Distance of the entry in the given slot from its ideal slot.
+func (t *concurrentTable[TKey, TValue]) distance(entry *concurrentWord[TKey, TValue], index int) int {
+	return (index - int(entry.hash&t.mask)) & int(t.mask)
+}
+
+// Order of the stamp of the given shard, stamps are ordered before slots.
+func (t *concurrentTable[TKey, TValue]) stampOrder(shard int) uint64 {
+	return t.generation<<40 | uint64(shard)
+}
+
+// Order of the given slot.
+func (t *concurrentTable[TKey, TValue]) slotOrder(index int) uint64 {
+	return t.generation<<40 | uint64(len(t.stamps)+index)
+}
+
+// Mark a shard as frozen, or as copied, unless it already is.
+func (t *concurrentTable[TKey, TValue]) markShard(shard int, copied bool) {
+	for {
+		stamp := readWord(&t.stamps[shard])
+		if stamp.frozen && (stamp.copied || !copied) {
+			return
+		}
+		marked := &concurrentWord[TKey, TValue]{stamp: stamp.stamp + 1, frozen: true, copied: copied}
+		if kcas([]kcasEntry[TKey, TValue]{{word: &t.stamps[shard], order: t.stampOrder(shard), old: stamp, new: marked}}) {
+			return
+		}
+	}
+}
+
+// Read a slot, reading the stamp of its shard first if it wasn't read yet.
+// Slots written by the operation hold their new content, if the operation tracks them.
+func (op *concurrentOp[TKey, TValue]) read(index int) *concurrentWord[TKey, TValue] {
+	if position, written := op.pending[index]; written {
+		return op.writes[position].new
+	}
+	shard := index / concurrentShardSize
+	if !slices.Contains(op.shards, shard) {
+		stamp := readWord(&op.table.stamps[shard])
+		op.shards = append(op.shards, shard)
+		op.stamps = append(op.stamps, stamp)
+		op.modified = append(op.modified, false)
+		op.frozen = op.frozen || stamp.frozen
+	}
+	entry := readWord(&op.table.slots[index])
+	yieldStep()
+	return entry
+}
+
+// Record the write of a slot which was read.
+func (op *concurrentOp[TKey, TValue]) write(index int, old, new *concurrentWord[TKey, TValue]) {
+	if op.pending != nil {
+		if position, written := op.pending[index]; written {
+			op.writes[position].new = new
+			return
+		}
+		op.pending[index] = len(op.writes)
+	}
+	op.writes = append(op.writes, kcasEntry[TKey, TValue]{word: &op.table.slots[index], order: op.table.slotOrder(index), old: old, new: new})
+	op.modified[slices.Index(op.shards, index/concurrentShardSize)] = true
+}
+
+// Find the slot of the key: the slot holding it, or the slot where it belongs, which is either empty or holds an entry
+// closer to its ideal slot. Returns the slot index, the probe distance and the slot content, or -1 if the table is full.
+func (op *concurrentOp[TKey, TValue]) probe(key TKey, hash uint64) (int, int, *concurrentWord[TKey, TValue]) {
+	t := op.table
+	index := int(hash & t.mask)
+	for distance := range t.slots {
+		entry := op.read(index)
+		if entry == nil || entry.hash == hash && entry.key == key || t.distance(entry, index) < distance {
+			return index, distance, entry
+		}
+		index = (index + 1) & int(t.mask)
+	}
+	return -1, 0, nil
+}
+
+// Record the placement of an entry at the given slot and probe distance: the entries from the slot to the next empty
+// one are displaced, each one taking the slot of the next entry closer to its ideal slot.
+// Returns false if the table is full.
+func (op *concurrentOp[TKey, TValue]) place(index, distance int, entry *concurrentWord[TKey, TValue]) bool {
+	t := op.table
+	for range t.slots {
+		current := op.read(index)
+		if current == nil {
+			op.write(index, nil, entry)
+			return true
+		}
+		if currentDistance := t.distance(current, index); currentDistance < distance {
+			op.write(index, current, entry)
+			entry, distance = current, currentDistance
+		}
+		index = (index + 1) & int(t.mask)
+		distance++
+	}
+	return false
+}
+
+// Apply the recorded writes, if none of the shards read changed meanwhile.
+func (op *concurrentOp[TKey, TValue]) commit() bool {
+	entries := op.writes
+	for i, shard := range op.shards {
+		stamp := op.stamps[i]
+		if op.modified[i] {
+			stamp = &concurrentWord[TKey, TValue]{stamp: stamp.stamp + 1}
+		}
+		entries = append(entries, kcasEntry[TKey, TValue]{word: &op.table.stamps[shard], order: op.table.stampOrder(shard), old: op.stamps[i], new: stamp})
+	}
+	return kcas(entries)
+}
+
+// Check whether an entry expired at the time of the operation.
+func (op *concurrentOp[TKey, TValue]) expired(entry *concurrentWord[TKey, TValue]) bool {
+	if entry.deadline == 0 {
+		return false
+	}
+	if op.now == 0 {
+		op.now = time.Now().UnixNano()
+	}
+	return op.now >= entry.deadline
+}
+
+// Check that none of the shards read changed since they were read.
+func (op *concurrentOp[TKey, TValue]) validate() bool {
+	for i, shard := range op.shards {
+		if readWord(&op.table.stamps[shard]) != op.stamps[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,362 @@
+package hashmap
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Apply random operations to a concurrent hashmap and to a native map from a single goroutine, then compare their content.
+func TestConcurrentSequential(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := NewConcurrent(WithInitialCapacity[int, int](8))
+	expected := map[int]int{}
+
+	for i := range 200_000 {
+		key := rng.Intn(20_000)
+		switch op := rng.Intn(10); {
+		case op < 6:
+			m.Set(key, i)
+			expected[key] = i
+		case op < 9:
+			m.Delete(key)
+			delete(expected, key)
+		default:
+			value, found := m.TryGet(key)
+			expectedValue, expectedFound := expected[key]
+			if found != expectedFound || value != expectedValue {
+				t.Fatalf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+			}
+		}
+	}
+
+	if m.Len() != len(expected) {
+		t.Errorf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+	}
+	entries := m.GetEntries()
+	if len(entries) != len(expected) {
+		t.Errorf("invalid entries length. expected=%d, got=%d", len(expected), len(entries))
+	}
+	for _, kv := range entries {
+		if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+			t.Errorf("invalid entry for key=%d. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+		}
+	}
+	checkConcurrentTable(t, m)
+}
+
+// Goroutines apply random operations to their own keys while the table is resized, then their content is checked.
+// Run with -race to also check memory accesses.
+func TestConcurrentStress(t *testing.T) {
+	const goroutines = 8
+	m := NewConcurrent(WithInitialCapacity[int, int](8))
+	expected := make([]map[int]int, goroutines)
+
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		expected[g] = map[int]int{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(g)))
+			for i := range 50_000 {
+				key := g*1_000_000 + rng.Intn(5_000)
+				switch op := rng.Intn(10); {
+				case op < 6:
+					m.Set(key, i)
+					expected[g][key] = i
+				case op < 9:
+					m.Delete(key)
+					delete(expected[g], key)
+				default:
+					value, found := m.TryGet(key)
+					expectedValue, expectedFound := expected[g][key]
+					if found != expectedFound || value != expectedValue {
+						t.Errorf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+						return
+					}
+				}
+				// Hot keys shared by all goroutines
+				m.Set(-rng.Intn(4)-1, i)
+				m.Delete(-rng.Intn(4) - 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, key := range []int{-1, -2, -3, -4} {
+		m.Delete(key)
+	}
+	expectedLength := 0
+	for g := range goroutines {
+		expectedLength += len(expected[g])
+		for key, expectedValue := range expected[g] {
+			if value, found := m.TryGet(key); !found || value != expectedValue {
+				t.Errorf("invalid lookup for key=%d. expected=(%d, true), got=(%d, %t)", key, expectedValue, value, found)
+			}
+		}
+	}
+	if m.Len() != expectedLength {
+		t.Errorf("invalid length. expected=%d, got=%d", expectedLength, m.Len())
+	}
+	if entries := m.GetEntries(); len(entries) != expectedLength {
+		t.Errorf("invalid entries length. expected=%d, got=%d", expectedLength, len(entries))
+	}
+	checkConcurrentTable(t, m)
+}
+
+// Goroutines write their own keys while the hashmap is cleared: keys written after the last Clear must be kept.
+func TestConcurrentClear(t *testing.T) {
+	m := NewConcurrent(WithInitialCapacity[int, int](8))
+	for i := range 1000 {
+		m.Set(i, i)
+	}
+	m.Clear()
+	if m.Len() != 0 || len(m.GetEntries()) != 0 || m.Get(1) != 0 {
+		t.Fatalf("entries left by Clear: %v", m.GetEntries())
+	}
+
+	var wg sync.WaitGroup
+	var clearing atomic.Bool
+	clearing.Store(true)
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20_000 {
+				m.Set(g*1_000_000+i%3_000, i)
+				if i%7 == 0 {
+					m.Delete(g*1_000_000 + i%5_000)
+				}
+			}
+		}()
+	}
+	cleared := make(chan struct{})
+	go func() {
+		defer close(cleared)
+		for clearing.Load() {
+			m.Clear()
+			yieldStep()
+		}
+	}()
+	wg.Wait()
+	clearing.Store(false)
+	<-cleared
+	m.Clear()
+
+	for g := range 4 {
+		m.Set(g, g)
+	}
+	if m.Len() != 4 || len(m.GetEntries()) != 4 {
+		t.Errorf("invalid length after clears. expected=4, got=%d (%d entries)", m.Len(), len(m.GetEntries()))
+	}
+	checkConcurrentTable(t, m)
+}
+
+func TestConcurrentTTL(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	m.SetWithTTL("expired", 1, -time.Second)
+	m.SetWithTTL("live", 2, time.Hour)
+	m.SetWithTTL("updated", 3, -time.Second)
+	m.Set("updated", 4) // Clears the TTL
+	m.Set("plain", 5)
+
+	if _, found := m.TryGet("expired"); found {
+		t.Error("expired entry found")
+	}
+	if value, found := m.TryGet("live"); !found || value != 2 {
+		t.Errorf("invalid live entry. expected=(2, true), got=(%d, %t)", value, found)
+	}
+	if len(m.GetEntries()) != 3 || m.Len() != 3 {
+		t.Errorf("invalid entries, the expired one must be removed by the lookup. len=%d, entries=%v", m.Len(), m.GetEntries())
+	}
+
+	m.SetWithTTL("short", 6, time.Millisecond)
+	m.SetWithTTL("other", 7, -time.Second)
+	time.Sleep(2 * time.Millisecond)
+	if removed := m.DeleteExpired(); removed != 2 || m.Len() != 3 {
+		t.Errorf("invalid expired entries removal. expected=2, got=%d (len=%d)", removed, m.Len())
+	}
+	m.SetWithTTL("again", 8, -time.Second)
+	m.Delete("again")
+	if m.Len() != 3 {
+		t.Errorf("expired entry not removed by Delete. len=%d", m.Len())
+	}
+	checkConcurrentTable(t, m)
+}
+
+// Check that the table of a quiescent concurrent hashmap only holds its entries, without tombstones,
+// and that they are placed with Robin Hood hashing: the probe distance grows by at most one from a slot to the next.
+func checkConcurrentTable[TKey comparable, TValue any](t *testing.T, m *ConcurrentHashmap[TKey, TValue]) {
+	table := m.table.Load()
+	if table.next.Load() != nil {
+		t.Fatal("resize left unfinished")
+	}
+	entries := 0
+	for i := range table.slots {
+		entry := table.slots[i].Load()
+		if entry == nil {
+			continue
+		}
+		entries++
+		if entry.rdcss != nil || entry.kcas != nil {
+			t.Fatalf("operation left unfinished in slot %d", i)
+		}
+		previous := table.slots[(i-1)&int(table.mask)].Load()
+		if distance := table.distance(entry, i); distance > 0 && (previous == nil || table.distance(previous, (i-1)&int(table.mask)) < distance-1) {
+			t.Fatalf("Robin Hood invariant broken at slot %d, distance=%d", i, distance)
+		}
+	}
+	if entries != m.Len() {
+		t.Errorf("invalid slots usage. expected=%d, got=%d", m.Len(), entries)
+	}
+}
+
+// Operation of a concurrent history
+type historyOp struct {
+	kind     byte // 'g'et, 's'et or 'd'elete
+	value    int  // Value set, or value read
+	found    bool // Whether get found the key
+	invoke   int64
+	response int64
+}
+
+// State of a single key
+type registerState struct {
+	value   int
+	present bool
+}
+
+// Goroutines apply operations to a few keys while the table is resized, and the history of each key
+// is checked to be linearizable.
+func TestConcurrentLinearizability(t *testing.T) {
+	const goroutines = 4
+	const opsPerGoroutine = 20
+	const keys = 4
+
+	for round := range 2_000 {
+		m := NewConcurrent(WithInitialCapacity[int, int](8))
+		var clock atomic.Int64
+		histories := make([][][]historyOp, goroutines) // Goroutine, key, operations
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for g := range goroutines {
+			histories[g] = make([][]historyOp, keys)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				rng := rand.New(rand.NewSource(int64(round*goroutines + g)))
+				for i := range opsPerGoroutine {
+					key := rng.Intn(keys)
+					op := historyOp{invoke: clock.Add(1)}
+					switch rng.Intn(3) {
+					case 0:
+						op.kind = 's'
+						op.value = g*opsPerGoroutine + i + 1
+						m.Set(key, op.value)
+					case 1:
+						op.kind = 'd'
+						m.Delete(key)
+					default:
+						op.kind = 'g'
+						op.value, op.found = m.TryGet(key)
+					}
+					op.response = clock.Add(1)
+					histories[g][key] = append(histories[g][key], op)
+
+					// Trigger resizes
+					for j := range rng.Intn(8) {
+						m.Set(1_000+g*1_000+i*10+j, 0)
+					}
+				}
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		for key := range keys {
+			var ops []historyOp
+			for g := range goroutines {
+				ops = append(ops, histories[g][key]...)
+			}
+			if !isLinearizable(ops) {
+				t.Fatalf("non linearizable history for key=%d in round %d: %+v", key, round, ops)
+			}
+		}
+	}
+}
+
+// Check whether the operations of a key can be ordered so that each one takes effect between its invocation and its
+// response, and the results are the ones of a sequential map. Implements the Wing & Gong search with memoization.
+func isLinearizable(ops []historyOp) bool {
+	if len(ops) > 64 {
+		panic("history too long")
+	}
+	type memoKey struct {
+		linearized uint64
+		state      registerState
+	}
+	all := uint64(1)<<len(ops) - 1
+	failed := map[memoKey]bool{}
+
+	var search func(linearized uint64, state registerState) bool
+	search = func(linearized uint64, state registerState) bool {
+		if linearized == all {
+			return true
+		}
+		key := memoKey{linearized, state}
+		if failed[key] {
+			return false
+		}
+
+		// An operation can be linearized next if no pending operation completed before it was invoked
+		minResponse := int64(1<<63 - 1)
+		for i, op := range ops {
+			if linearized&(1<<i) == 0 {
+				minResponse = min(minResponse, op.response)
+			}
+		}
+		for i, op := range ops {
+			if linearized&(1<<i) != 0 || op.invoke > minResponse {
+				continue
+			}
+			next := state
+			switch op.kind {
+			case 's':
+				next = registerState{op.value, true}
+			case 'd':
+				next = registerState{}
+			default:
+				if op.found != state.present || op.found && op.value != state.value {
+					continue
+				}
+			}
+			if search(linearized|1<<i, next) {
+				return true
+			}
+		}
+		failed[key] = true
+		return false
+	}
+	return search(0, registerState{})
+}
+
+func TestIsLinearizable(t *testing.T) {
+	// Set(1) completes before a get starts, which can't miss the key
+	ops := []historyOp{
+		{kind: 's', value: 1, invoke: 1, response: 2},
+		{kind: 'g', found: false, invoke: 3, response: 4},
+	}
+	if isLinearizable(ops) {
+		t.Error("stale read must not be linearizable")
+	}
+	// Overlapping operations can be ordered either way
+	ops[1].invoke = 1
+	if !isLinearizable(ops) {
+		t.Error("concurrent read must be linearizable")
+	}
+}
@@ -5,13 +5,13 @@ type HashMapConfig[TKey comparable, TValue any] func(*Hashmap[TKey, TValue])
 
 // Specify a custom load percentage, beyond which the hashmap will grow in size.
 //
-// This must be less than 100, or the default percentage will be applied.
+// This must be less than 100, or the default percentage will be applied. It is at least 1.
 func WithMaxLoadPercentage[TKey comparable, TValue any](loadPercentage uint) HashMapConfig[TKey, TValue] {
 	var loadFactor float32
 	if loadPercentage >= 100 {
 		loadFactor = defaultLoadFactor
 	} else {
-		loadFactor = float32(loadPercentage) / 100
+		loadFactor = float32(max(loadPercentage, 1)) / 100
 	}
 
 	return func(hmap *Hashmap[TKey, TValue]) {
@@ -28,7 +28,7 @@ func WithInitialCapacity[TKey comparable, TValue any](initialCapacity uint) Hash
 	}
 
 	return func(hmap *Hashmap[TKey, TValue]) {
-		hmap.storage = make([]mapEntry[TKey, TValue], initialCapacity)
+		hmap.initialCapacity = initialCapacity
 	}
 }
 
@@ -38,3 +38,44 @@ func WithHashFunc[TKey comparable, TValue any](hashFunc func(uintptr, uintptr) u
 		hmap.hashFunc = hashFunc
 	}
 }
+
+// Specify the storage layout, see Layout.
+//
+// The custom load percentage only applies to the RobinHood and RobinHoodSoA layouts, Swiss tables grow at 7/8 load and cuckoo tables at 90%.
+func WithLayout[TKey comparable, TValue any](layout Layout) HashMapConfig[TKey, TValue] {
+	return func(hmap *Hashmap[TKey, TValue]) {
+		hmap.layout = layout
+	}
+}
+
+// Allocate the entries storage outside of the Go heap, using anonymous memory mappings.
+//
+// Large storages then don't count in the heap size, which drives garbage collection frequency. This only applies to
+// the RobinHood layout on unix systems, when keys and values don't contain pointers (the garbage collector would not
+// see them). Otherwise, the storage is allocated on the Go heap. Close must be called to release the memory.
+//
+// SmallHashmap applies it to the hashmap created when its inline array overflows, and must be closed too.
+// ReadMostlyHashmap ignores it.
+func WithOffHeapStorage[TKey comparable, TValue any]() HashMapConfig[TKey, TValue] {
+	return func(hmap *Hashmap[TKey, TValue]) {
+		hmap.offHeap = true
+	}
+}
+
+// Maintain the fingerprint of the hashmap on each write, in constant time, see Hashmap.IncrementalFingerprint.
+//
+// Writes then look up the previous value of keys to subtract its hash, like when hooks are registered.
+func WithIncrementalFingerprint[TKey comparable, TValue any](keyHash func(TKey) uint64, valueHash func(TValue) uint64) HashMapConfig[TKey, TValue] {
+	return func(hmap *Hashmap[TKey, TValue]) {
+		hmap.enableFingerprint(&fingerprintState[TKey, TValue]{keyHash: keyHash, valueHash: valueHash})
+	}
+}
+
+// Specify the seed mixed with keys before hashing them.
+//
+// A random seed is generated by default, except for IntHashmap which doesn't seed keys by default.
+func WithSeed[TKey comparable, TValue any](seed uintptr) HashMapConfig[TKey, TValue] {
+	return func(hmap *Hashmap[TKey, TValue]) {
+		hmap.hashSeed = seed
+	}
+}
@@ -0,0 +1,247 @@
+package hashmap
+
+const (
+	cuckooBucketSize = 4
+	cuckooStashSize  = 4
+	cuckooMaxKicks   = 256 // Number of evictions before an insertion is considered to be looping
+	cuckooMaxLoad    = 0.9
+	cuckooMaxGrowth  = 64 // Maximum number of buckets per entry, a larger table means that the hash function is broken
+)
+
+// Cuckoo table slot
+type cuckooSlot[TKey, TValue any] struct {
+	key   TKey
+	value TValue
+	alive bool
+}
+
+// Bucketized cuckoo hashing storage engine.
+//
+// Every key has two candidate buckets of 4 slots, one per hash function: a lookup reads at most these two buckets,
+// then the stash, a handful of entries that could not be placed, which is almost always empty.
+// An insertion into two full buckets evicts entries to their other bucket. If evictions loop, the entry is
+// put in the stash, and the table is resized when the stash is full.
+type cuckooTable[TKey comparable, TValue any] struct {
+	slots      []cuckooSlot[TKey, TValue]
+	stash      []cuckooSlot[TKey, TValue]
+	bucketMask uint64
+	length     int
+	hash       func(TKey) uint64 // Same as the one used by the hashmap
+	altHash    func(TKey) uint64 // Second hash function, with another seed
+	rngState   uint64            // Used to pick evicted slots
+}
+
+// Create a cuckoo table with room for at least the given number of slots.
+func newCuckooTable[TKey comparable, TValue any](capacity int, hash, altHash func(TKey) uint64) *cuckooTable[TKey, TValue] {
+	buckets := 1
+	for buckets*cuckooBucketSize < capacity {
+		buckets *= 2
+	}
+	t := &cuckooTable[TKey, TValue]{
+		hash:     hash,
+		altHash:  altHash,
+		rngState: 0x9e3779b97f4a7c15,
+	}
+	t.allocate(buckets)
+	return t
+}
+
+func (t *cuckooTable[TKey, TValue]) get(key TKey, hash uint64) (TValue, bool) {
+	slot := t.find(key, hash)
+	if slot != nil {
+		return slot.value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+func (t *cuckooTable[TKey, TValue]) set(key TKey, hash uint64, value TValue) bool {
+	if slot := t.find(key, hash); slot != nil {
+		slot.value = value
+		return false
+	}
+
+	if float64(t.length+1) > float64(len(t.slots))*cuckooMaxLoad {
+		t.grow(cuckooSlot[TKey, TValue]{})
+	}
+	t.length++
+	if homeless, placed := t.insert(key, hash, value); !placed {
+		t.grow(homeless)
+	}
+	return true
+}
+
+func (t *cuckooTable[TKey, TValue]) delete(key TKey, hash uint64) bool {
+	slot := t.find(key, hash)
+	if slot == nil {
+		return false
+	}
+	t.length--
+
+	if index := t.stashIndex(slot); index >= 0 {
+		last := len(t.stash) - 1
+		t.stash[index] = t.stash[last]
+		t.stash[last] = cuckooSlot[TKey, TValue]{}
+		t.stash = t.stash[:last]
+		return true
+	}
+	*slot = cuckooSlot[TKey, TValue]{}
+	return true
+}
+
+func (t *cuckooTable[TKey, TValue]) clear() {
+	t.allocate(len(t.slots) / cuckooBucketSize)
+}
+
+func (t *cuckooTable[TKey, TValue]) capacity() int {
+	return len(t.slots)
+}
+
+func (t *cuckooTable[TKey, TValue]) forEach(fn func(key TKey, value TValue)) {
+	for _, slot := range t.slots {
+		if slot.alive {
+			fn(slot.key, slot.value)
+		}
+	}
+	for _, slot := range t.stash {
+		fn(slot.key, slot.value)
+	}
+}
+
+// Main lookup function, get a pointer to the slot holding the given key, or nil.
+func (t *cuckooTable[TKey, TValue]) find(key TKey, hash uint64) *cuckooSlot[TKey, TValue] {
+	if slot := t.findInBucket(key, hash&t.bucketMask); slot != nil {
+		return slot
+	}
+	if slot := t.findInBucket(key, t.altHash(key)&t.bucketMask); slot != nil {
+		return slot
+	}
+	for i := range t.stash {
+		if t.stash[i].key == key {
+			return &t.stash[i]
+		}
+	}
+	return nil
+}
+
+// Get a pointer to the slot holding the given key in a bucket, or nil.
+func (t *cuckooTable[TKey, TValue]) findInBucket(key TKey, bucket uint64) *cuckooSlot[TKey, TValue] {
+	slots := t.slots[bucket*cuckooBucketSize : bucket*cuckooBucketSize+cuckooBucketSize]
+	for i := range slots {
+		if slots[i].alive && slots[i].key == key {
+			return &slots[i]
+		}
+	}
+	return nil
+}
+
+// Place a new entry, evicting other entries if both of its buckets are full.
+//
+// If evictions loop, the last evicted entry goes to the stash. When the stash is full, it is returned instead.
+func (t *cuckooTable[TKey, TValue]) insert(key TKey, hash uint64, value TValue) (cuckooSlot[TKey, TValue], bool) {
+	entry := cuckooSlot[TKey, TValue]{key, value, true}
+	bucket := hash & t.bucketMask
+	if t.placeInBucket(entry, bucket) {
+		return cuckooSlot[TKey, TValue]{}, true
+	}
+	bucket = t.altHash(key) & t.bucketMask
+	if t.placeInBucket(entry, bucket) {
+		return cuckooSlot[TKey, TValue]{}, true
+	}
+
+	for range cuckooMaxKicks {
+		// Evict a random slot of the bucket, and move its entry to its other bucket
+		index := bucket*cuckooBucketSize + t.random()%cuckooBucketSize
+		entry, t.slots[index] = t.slots[index], entry
+
+		bucket = t.otherBucket(entry.key, bucket)
+		if t.placeInBucket(entry, bucket) {
+			return cuckooSlot[TKey, TValue]{}, true
+		}
+	}
+
+	if len(t.stash) < cuckooStashSize {
+		t.stash = append(t.stash, entry)
+		return cuckooSlot[TKey, TValue]{}, true
+	}
+	return entry, false
+}
+
+// Put an entry in a free slot of the bucket, if any.
+func (t *cuckooTable[TKey, TValue]) placeInBucket(entry cuckooSlot[TKey, TValue], bucket uint64) bool {
+	slots := t.slots[bucket*cuckooBucketSize : bucket*cuckooBucketSize+cuckooBucketSize]
+	for i := range slots {
+		if !slots[i].alive {
+			slots[i] = entry
+			return true
+		}
+	}
+	return false
+}
+
+// Get the candidate bucket of the key that isn't the given one.
+func (t *cuckooTable[TKey, TValue]) otherBucket(key TKey, bucket uint64) uint64 {
+	first := t.hash(key) & t.bucketMask
+	if first != bucket {
+		return first
+	}
+	return t.altHash(key) & t.bucketMask
+}
+
+// Get the index of the given slot in the stash, or -1 if it is a bucket slot.
+func (t *cuckooTable[TKey, TValue]) stashIndex(slot *cuckooSlot[TKey, TValue]) int {
+	for i := range t.stash {
+		if &t.stash[i] == slot {
+			return i
+		}
+	}
+	return -1
+}
+
+// Allocate empty storage with the given number of buckets.
+func (t *cuckooTable[TKey, TValue]) allocate(buckets int) {
+	t.slots = make([]cuckooSlot[TKey, TValue], buckets*cuckooBucketSize)
+	t.stash = nil
+	t.bucketMask = uint64(buckets - 1)
+	t.length = 0
+}
+
+// Allocate a new storage, twice as big as previous storage, and reinsert all entries along with the given one.
+// The storage keeps doubling until every entry could be placed.
+func (t *cuckooTable[TKey, TValue]) grow(extra cuckooSlot[TKey, TValue]) {
+	entries := make([]cuckooSlot[TKey, TValue], 0, t.length)
+	t.forEach(func(key TKey, value TValue) {
+		entries = append(entries, cuckooSlot[TKey, TValue]{key, value, true})
+	})
+	if extra.alive {
+		entries = append(entries, extra)
+	}
+
+	buckets := len(t.slots) / cuckooBucketSize
+	length := t.length
+	for {
+		buckets *= 2
+		if buckets > cuckooMaxGrowth*len(entries) {
+			panic("hashmap: unable to place entries in the cuckoo table, the hash function yields too many collisions")
+		}
+		t.allocate(buckets)
+		placed := true
+		for _, entry := range entries {
+			if _, placed = t.insert(entry.key, t.hash(entry.key), entry.value); !placed {
+				break
+			}
+		}
+		if placed {
+			t.length = length
+			return
+		}
+	}
+}
+
+// Get a pseudo random number (xorshift).
+func (t *cuckooTable[TKey, TValue]) random() uint64 {
+	t.rngState ^= t.rngState << 13
+	t.rngState ^= t.rngState >> 7
+	t.rngState ^= t.rngState << 17
+	return t.rngState
+}
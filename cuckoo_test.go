@@ -0,0 +1,41 @@
+package hashmap
+
+import "testing"
+
+func TestCuckooStash(t *testing.T) {
+	// Every key has the same 2 buckets: 8 slots then the stash
+	table := newCuckooTable[int, int](64, func(int) uint64 { return 0 }, func(int) uint64 { return 1 })
+	for i := range cuckooBucketSize*2 + cuckooStashSize {
+		if !table.set(i, 0, i*10) {
+			t.Fatalf("key=%d was not inserted", i)
+		}
+	}
+	if len(table.stash) != cuckooStashSize {
+		t.Errorf("invalid stash length. expected=%d, got=%d", cuckooStashSize, len(table.stash))
+	}
+
+	for i := range cuckooBucketSize*2 + cuckooStashSize {
+		if value, found := table.get(i, 0); !found || value != i*10 {
+			t.Errorf("retrieved invalid value for key=%d. expected=%d, got=(%d, %t)", i, i*10, value, found)
+		}
+	}
+
+	stashedKey := table.stash[0].key
+	if !table.delete(stashedKey, 0) {
+		t.Errorf("stashed key=%d was not deleted", stashedKey)
+	}
+	if _, found := table.get(stashedKey, 0); found {
+		t.Errorf("stashed key=%d was found", stashedKey)
+	}
+	if !table.set(stashedKey, 0, 1) {
+		t.Errorf("stashed key=%d was not inserted", stashedKey)
+	}
+
+	// The table can't grow its way out of a broken hash function
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic when entries can't be placed")
+		}
+	}()
+	table.set(1000, 0, 0)
+}
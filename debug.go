@@ -0,0 +1,10 @@
+//go:build hashmapdebug
+
+package hashmap
+
+// Panic if the given precomputed hash doesn't match the key, the check is only enabled by the hashmapdebug build tag.
+func (m *Hashmap[TKey, TValue]) checkHash(key TKey, hash uint64) {
+	if hash != m.hash(key) {
+		panic("hashmap: precomputed hash doesn't match the key, it must be computed by a hashmap with the same seed and hash function")
+	}
+}
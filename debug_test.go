@@ -0,0 +1,28 @@
+//go:build hashmapdebug
+
+package hashmap
+
+import "testing"
+
+func TestCheckHash(t *testing.T) {
+	m := New[string, int]()
+	defer func() {
+		if recover() == nil {
+			t.Error("mismatching hash not detected")
+		}
+	}()
+	m.SetHashed("key", m.Hash("key")+1, 1)
+}
+
+func TestCheckHashZeroValue(t *testing.T) {
+	var m Hashmap[string, int]
+	m.SetHashed("key", m.Hash("key"), 1) // Same seed
+
+	var other Hashmap[string, int]
+	defer func() {
+		if recover() == nil {
+			t.Error("hash computed by another zero value hashmap not detected")
+		}
+	}()
+	other.SetHashed("key", m.Hash("key"), 1)
+}
@@ -0,0 +1,225 @@
+package diskmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestVariableSizeTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table")
+	w, err := Create(path, 0, 0)
+	if err != nil {
+		t.Fatalf("writer creation failed: %v", err)
+	}
+	for i := range 1000 {
+		if err := w.Add([]byte("key"+strconv.Itoa(i)), []byte("value"+strconv.Itoa(i*i))); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if err := w.Add([]byte("empty"), nil); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	table, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer table.Close()
+
+	if table.Len() != 1001 {
+		t.Errorf("invalid length. expected=1001, got=%d", table.Len())
+	}
+	for i := range 1000 {
+		key := "key" + strconv.Itoa(i)
+		value, found := table.TryGet([]byte(key))
+		if !found {
+			t.Errorf("key=%s not found", key)
+		}
+		if expected := "value" + strconv.Itoa(i*i); string(value) != expected {
+			t.Errorf("retrieved invalid value for key=%s. expected=%s, got=%s", key, expected, value)
+		}
+	}
+	if value, found := table.TryGet([]byte("empty")); !found || len(value) != 0 {
+		t.Errorf("invalid empty value. found=%t, got=%q", found, value)
+	}
+	if value, found := table.TryGet([]byte("notfound")); found || value != nil {
+		t.Errorf("key=notfound was found")
+	}
+}
+
+func TestFixedSizeTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table")
+	w, err := Create(path, 8, 4)
+	if err != nil {
+		t.Fatalf("writer creation failed: %v", err)
+	}
+	for i := range uint64(500) {
+		if err := w.Add(binary.LittleEndian.AppendUint64(nil, i), binary.LittleEndian.AppendUint32(nil, uint32(i*3))); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	table, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer table.Close()
+
+	for i := range uint64(500) {
+		value := table.Get(binary.LittleEndian.AppendUint64(nil, i))
+		if len(value) != 4 || binary.LittleEndian.Uint32(value) != uint32(i*3) {
+			t.Errorf("retrieved invalid value for key=%d. got=%v", i, value)
+		}
+	}
+	if value := table.Get(binary.LittleEndian.AppendUint64(nil, 9999)); value != nil {
+		t.Errorf("key=9999 was found")
+	}
+}
+
+func TestEmptyTable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table")
+	w, err := Create(path, 0, 0)
+	if err != nil {
+		t.Fatalf("writer creation failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	table, err := Open(path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer table.Close()
+
+	if _, found := table.TryGet([]byte("key")); found {
+		t.Errorf("key was found in an empty table")
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	w := NewWriter(io.Discard, 4, 0)
+	if err := w.Add([]byte("key1"), []byte("value")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := w.Add([]byte("key1"), []byte("value")); !errors.Is(err, ErrDuplicateKey) {
+		t.Errorf("invalid error for duplicate key. expected=%v, got=%v", ErrDuplicateKey, err)
+	}
+	if err := w.Add([]byte("key"), []byte("value")); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("invalid error for key size. expected=%v, got=%v", ErrInvalidSize, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := w.Add([]byte("key2"), []byte("value")); !errors.Is(err, ErrClosed) {
+		t.Errorf("invalid error for closed writer. expected=%v, got=%v", ErrClosed, err)
+	}
+}
+
+func TestOpenInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "table")
+	if err := os.WriteFile(path, make([]byte, headerSize*2), 0o644); err != nil {
+		t.Fatalf("file creation failed: %v", err)
+	}
+	if _, err := Open(path); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("invalid error. expected=%v, got=%v", ErrInvalidFormat, err)
+	}
+}
+
+// Header fields of a valid table are corrupted, Open must reject them instead of reading out of the file.
+func TestOpenCorruptHeader(t *testing.T) {
+	cases := []struct {
+		name   string
+		offset int
+		value  uint64
+	}{
+		{"OverflowingCapacity", headerCapacity, 1 << 60}, // capacity*slotSize wraps to 0
+		{"CapacityAboveFileSize", headerCapacity, 1 << 20},
+		{"DataOffset", headerDataOffset, 1 << 20},
+		{"MaxProbe", headerMaxProbe, 1 << 40},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "table")
+			w, err := Create(path, 0, 0)
+			if err != nil {
+				t.Fatalf("writer creation failed: %v", err)
+			}
+			if err := w.Add([]byte("key"), []byte("value")); err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			binary.LittleEndian.PutUint64(data[c.offset:], c.value)
+			if c.name == "OverflowingCapacity" {
+				binary.LittleEndian.PutUint64(data[headerDataOffset:], headerSize)
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+
+			if _, err := Open(path); !errors.Is(err, ErrInvalidFormat) {
+				t.Errorf("invalid error. expected=%v, got=%v", ErrInvalidFormat, err)
+			}
+		})
+	}
+}
+
+// Record offsets of a valid table are corrupted, lookups must report the key as missing instead of panicking.
+func TestCorruptRecordOffset(t *testing.T) {
+	for _, keySize := range []int{0, 3} {
+		t.Run("KeySize"+strconv.Itoa(keySize), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "table")
+			w, err := Create(path, keySize, 0)
+			if err != nil {
+				t.Fatalf("writer creation failed: %v", err)
+			}
+			if err := w.Add([]byte("key"), []byte("value")); err != nil {
+				t.Fatalf("add failed: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("close failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			dataOffset := binary.LittleEndian.Uint64(data[headerDataOffset:])
+			for slot := uint64(headerSize); slot < dataOffset; slot += slotSize {
+				if binary.LittleEndian.Uint64(data[slot+8:]) != 0 {
+					binary.LittleEndian.PutUint64(data[slot+8:], ^uint64(0)) // Record offset wrapping when read
+				}
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+
+			table, err := Open(path)
+			if err != nil {
+				t.Fatalf("open failed: %v", err)
+			}
+			defer table.Close()
+			if value, found := table.TryGet([]byte("key")); found || value != nil {
+				t.Errorf("key with corrupted record found: %q", value)
+			}
+		})
+	}
+}
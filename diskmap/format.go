@@ -0,0 +1,43 @@
+// Package diskmap implements a read-only, memory-mapped hash table file format.
+//
+// A table is built once with a Writer, then opened with Open by any number of processes.
+// The file is memory-mapped, so its pages are shared through the page cache and lookups
+// return slices pointing directly into the mapping.
+//
+// The file is a Robin Hood hash table keyed by the stable hasher of the hasher package:
+//
+//	header  | 64 bytes, see the header* offsets
+//	slots   | capacity * 16 bytes: key hash (uint64) and record offset + 1 (uint64, 0 for an empty slot)
+//	records | key then value, each prefixed by its uint32 length when its size is not fixed
+//
+// All integers are little-endian.
+package diskmap
+
+import "errors"
+
+const (
+	magic         = "HMAPDISK"
+	formatVersion = 1
+
+	headerSize = 64
+	slotSize   = 16
+	lengthSize = 4 // Size of a record length prefix
+
+	headerVersion    = 8
+	headerKeySize    = 12
+	headerValueSize  = 16
+	headerSeed       = 24
+	headerCount      = 32
+	headerCapacity   = 40
+	headerMaxProbe   = 48
+	headerDataOffset = 56
+
+	maxLoadFactor = 0.8 // Maximum table load, tables are static so it can be higher than the Hashmap default
+)
+
+var (
+	ErrInvalidFormat = errors.New("diskmap: invalid table file")
+	ErrDuplicateKey  = errors.New("diskmap: duplicate key")
+	ErrInvalidSize   = errors.New("diskmap: key or value size mismatch")
+	ErrClosed        = errors.New("diskmap: writer closed")
+)
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package diskmap
+
+import (
+	"io"
+	"os"
+)
+
+// Read the file content in memory, memory mapping is only supported on unix systems.
+func mapFile(file *os.File, size int) ([]byte, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Release a mapping created by mapFile.
+func unmapFile(data []byte) error {
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package diskmap
+
+import (
+	"os"
+	"syscall"
+)
+
+// Map the file content in memory, read-only and shared with other processes.
+func mapFile(file *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+// Release a mapping created by mapFile.
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
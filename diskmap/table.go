@@ -0,0 +1,161 @@
+package diskmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Read-only table, memory-mapped from a file produced by a Writer.
+//
+// Lookups don't copy data: returned slices point into the mapping and are only valid until Close is called.
+// A Table is safe for concurrent use.
+type Table struct {
+	data      []byte // Whole file mapping
+	slots     []byte
+	records   []byte
+	keySize   int
+	valueSize int
+	seed      uint64
+	mask      uint64
+	count     int
+	maxProbe  uint64
+}
+
+// Open and memory-map the table file at the given path.
+func Open(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < headerSize {
+		return nil, ErrInvalidFormat
+	}
+
+	data, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		return nil, err
+	}
+	t, err := newTable(data)
+	if err != nil {
+		unmapFile(data)
+		return nil, err
+	}
+	return t, nil
+}
+
+// Parse and validate the header of a mapped table.
+func newTable(data []byte) (*Table, error) {
+	if string(data[:len(magic)]) != magic || binary.LittleEndian.Uint32(data[headerVersion:]) != formatVersion {
+		return nil, ErrInvalidFormat
+	}
+
+	capacity := binary.LittleEndian.Uint64(data[headerCapacity:])
+	dataOffset := binary.LittleEndian.Uint64(data[headerDataOffset:])
+	// The capacity is bounded by the file size before computing the slots size, which could overflow otherwise
+	if capacity == 0 || capacity&(capacity-1) != 0 || capacity > (uint64(len(data))-headerSize)/slotSize {
+		return nil, ErrInvalidFormat
+	}
+	maxProbe := binary.LittleEndian.Uint64(data[headerMaxProbe:])
+	if dataOffset != headerSize+capacity*slotSize || maxProbe >= capacity {
+		return nil, ErrInvalidFormat
+	}
+
+	return &Table{
+		data:      data,
+		slots:     data[headerSize:dataOffset],
+		records:   data[dataOffset:],
+		keySize:   int(binary.LittleEndian.Uint32(data[headerKeySize:])),
+		valueSize: int(binary.LittleEndian.Uint32(data[headerValueSize:])),
+		seed:      binary.LittleEndian.Uint64(data[headerSeed:]),
+		mask:      capacity - 1,
+		count:     int(binary.LittleEndian.Uint64(data[headerCount:])),
+		maxProbe:  maxProbe,
+	}, nil
+}
+
+// Get the value associated with the given key. nil is returned if the key doesn't exist.
+func (t *Table) Get(key []byte) []byte {
+	value, _ := t.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (t *Table) TryGet(key []byte) ([]byte, bool) {
+	hash := hasher.Sum64(key, t.seed)
+	index := hash & t.mask
+	// The entry can only be located within a range of t.maxProbe from its ideal index
+	for distance := uint64(0); distance <= t.maxProbe; distance++ {
+		slot := t.slots[index*slotSize : index*slotSize+slotSize]
+		offset := binary.LittleEndian.Uint64(slot[8:])
+		if offset == 0 {
+			return nil, false
+		}
+
+		slotHash := binary.LittleEndian.Uint64(slot)
+		if slotHash == hash {
+			recordKey, value, ok := t.readRecord(offset - 1)
+			if ok && bytes.Equal(recordKey, key) {
+				return value, true
+			}
+		} else if (index-slotHash)&t.mask < distance {
+			// Robin Hood invariant: the key would have taken this slot
+			return nil, false
+		}
+
+		index = (index + 1) & t.mask
+	}
+	return nil, false
+}
+
+// Get the number of entries stored in the table.
+func (t *Table) Len() int {
+	return t.count
+}
+
+// Unmap the table file. Slices returned by lookups must not be used afterwards.
+func (t *Table) Close() error {
+	if t.data == nil {
+		return nil
+	}
+	data := t.data
+	t.data, t.slots, t.records = nil, nil, nil
+	return unmapFile(data)
+}
+
+// Read the key and value of the record located at the given offset.
+func (t *Table) readRecord(offset uint64) ([]byte, []byte, bool) {
+	key, offset, ok := t.readField(offset, t.keySize)
+	if !ok {
+		return nil, nil, false
+	}
+	value, _, ok := t.readField(offset, t.valueSize)
+	return key, value, ok
+}
+
+// Read a key or value at the given offset, returning the offset of the next field.
+func (t *Table) readField(offset uint64, fixedSize int) ([]byte, uint64, bool) {
+	// Bounds are checked with subtractions, offsets read from a corrupted file could make additions wrap
+	size := uint64(len(t.records))
+	length := uint64(fixedSize)
+	if fixedSize == 0 {
+		if offset > size || lengthSize > size-offset {
+			return nil, 0, false
+		}
+		length = uint64(binary.LittleEndian.Uint32(t.records[offset:]))
+		offset += lengthSize
+	}
+	if offset > size || length > size-offset {
+		return nil, 0, false
+	}
+	end := offset + length
+	return t.records[offset:end:end], end, true
+}
@@ -0,0 +1,168 @@
+package diskmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+
+	"github.com/valsov/hashmap"
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Table slot, as stored in the file
+type slot struct {
+	hash   uint64
+	offset uint64 // Record offset + 1, 0 for an empty slot
+}
+
+// Table file builder.
+//
+// Entries are buffered in memory until Close is called, the table is then built and written at once.
+type Writer struct {
+	w         io.Writer
+	file      *os.File // Set when the writer owns the destination file
+	keySize   int
+	valueSize int
+	seed      uint64
+	slots     []slot // One per entry, not yet placed
+	records   []byte
+	keys      *hashmap.Hashmap[string, struct{}]
+	closed    bool
+}
+
+// Create a table writer to the given io.Writer.
+//
+// keySize and valueSize are the fixed sizes of every key and value, 0 allows variable sizes (length-prefixed).
+func NewWriter(w io.Writer, keySize, valueSize int) *Writer {
+	return &Writer{
+		w:         w,
+		keySize:   keySize,
+		valueSize: valueSize,
+		seed:      uint64(hasher.GenerateSeed()),
+		keys:      hashmap.New[string, struct{}](),
+	}
+}
+
+// Create a table file at the given path, see NewWriter. The file is closed by Writer.Close.
+func Create(path string, keySize, valueSize int) (*Writer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := NewWriter(file, keySize, valueSize)
+	w.file = file
+	return w, nil
+}
+
+// Add an entry to the table. Keys must be unique.
+func (w *Writer) Add(key, value []byte) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if !validSize(len(key), w.keySize) || !validSize(len(value), w.valueSize) {
+		return ErrInvalidSize
+	}
+	if _, found := w.keys.TryGet(string(key)); found {
+		return ErrDuplicateKey
+	}
+	w.keys.Set(string(key), struct{}{})
+
+	w.slots = append(w.slots, slot{
+		hash:   hasher.Sum64(key, w.seed),
+		offset: uint64(len(w.records)) + 1,
+	})
+	w.records = appendField(w.records, key, w.keySize)
+	w.records = appendField(w.records, value, w.valueSize)
+	return nil
+}
+
+// Build the table and write it. The writer can't be used afterwards.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+
+	err := w.write()
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Place the entries in a Robin Hood table and write the file content.
+func (w *Writer) write() error {
+	capacity := 1
+	for float64(len(w.slots)) > float64(capacity)*maxLoadFactor {
+		capacity *= 2
+	}
+	table := make([]slot, capacity)
+	mask := uint64(capacity - 1)
+	var maxProbe uint64
+
+	for _, entry := range w.slots {
+		index := entry.hash & mask
+		var distance uint64
+		for table[index].offset != 0 {
+			curSlotDistance := (index - table[index].hash) & mask
+			if distance > curSlotDistance {
+				// Insert entry in this slot and continue to find a new spot for the previous one
+				table[index], entry = entry, table[index]
+				maxProbe = max(maxProbe, distance)
+				distance = curSlotDistance
+			}
+			distance++
+			index = (index + 1) & mask
+		}
+		table[index] = entry
+		maxProbe = max(maxProbe, distance)
+	}
+
+	header := make([]byte, headerSize)
+	copy(header, magic)
+	binary.LittleEndian.PutUint32(header[headerVersion:], formatVersion)
+	binary.LittleEndian.PutUint32(header[headerKeySize:], uint32(w.keySize))
+	binary.LittleEndian.PutUint32(header[headerValueSize:], uint32(w.valueSize))
+	binary.LittleEndian.PutUint64(header[headerSeed:], w.seed)
+	binary.LittleEndian.PutUint64(header[headerCount:], uint64(len(w.slots)))
+	binary.LittleEndian.PutUint64(header[headerCapacity:], uint64(capacity))
+	binary.LittleEndian.PutUint64(header[headerMaxProbe:], maxProbe)
+	binary.LittleEndian.PutUint64(header[headerDataOffset:], uint64(headerSize+capacity*slotSize))
+
+	buf := bufio.NewWriter(w.w)
+	if _, err := buf.Write(header); err != nil {
+		return err
+	}
+	var encodedSlot [slotSize]byte
+	for _, entry := range table {
+		binary.LittleEndian.PutUint64(encodedSlot[:], entry.hash)
+		binary.LittleEndian.PutUint64(encodedSlot[8:], entry.offset)
+		if _, err := buf.Write(encodedSlot[:]); err != nil {
+			return err
+		}
+	}
+	if _, err := buf.Write(w.records); err != nil {
+		return err
+	}
+	return buf.Flush()
+}
+
+// Check the length of a key or value against the table's fixed size, 0 meaning any size.
+func validSize(length, fixedSize int) bool {
+	if fixedSize == 0 {
+		return length <= math.MaxUint32
+	}
+	return length == fixedSize
+}
+
+// Append a key or value to the records, prefixed by its length if its size is not fixed.
+func appendField(records, field []byte, fixedSize int) []byte {
+	if fixedSize == 0 {
+		records = binary.LittleEndian.AppendUint32(records, uint32(len(field)))
+	}
+	return append(records, field...)
+}
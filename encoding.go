@@ -0,0 +1,117 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"math"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Largest encoded capacity preserved by decoding when the entries don't need it, so that a small payload
+// can't request a huge allocation. Beyond it, the capacity is derived from the entries.
+const maxDecodedSpareCapacity = 1 << 16
+
+// Serialized representation of a hashmap.
+//
+// Only the portable configuration is kept: the hash seed and hash function are process specific
+// and are regenerated on decoding.
+type encodedHashmap[TKey comparable, TValue any] struct {
+	Layout     Layout
+	LoadFactor float32
+	Capacity   int
+	Entries    []KeyValue[TKey, TValue]
+}
+
+// Encode the hashmap entries and configuration using encoding/gob.
+//
+// Implements the gob.GobEncoder interface.
+func (m *Hashmap[TKey, TValue]) GobEncode() ([]byte, error) {
+	encoded := encodedHashmap[TKey, TValue]{
+		Layout:     m.layout,
+		LoadFactor: m.loadFactor,
+		Capacity:   m.capacity(),
+		Entries:    m.GetEntries(),
+	}
+	if encoded.Capacity == 0 {
+		// Zero value hashmap, not initialized yet
+		encoded.LoadFactor = defaultLoadFactor
+		encoded.Capacity = int(defaultInitialCapacity)
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(encoded); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode the hashmap entries and configuration produced by GobEncode, replacing any existing entry.
+//
+// The hash function of the receiver is kept if it was configured, otherwise the default one is used with a new seed.
+// The encoded capacity is kept, unless it is far larger than what the entries need, see decodedCapacity.
+// Implements the gob.GobDecoder interface.
+func (m *Hashmap[TKey, TValue]) GobDecode(data []byte) error {
+	var encoded encodedHashmap[TKey, TValue]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&encoded); err != nil {
+		return err
+	}
+
+	if encoded.LoadFactor < minLoadFactor || encoded.LoadFactor >= 1 {
+		return errors.New("hashmap: invalid encoded load factor")
+	}
+	if encoded.Capacity <= 0 || encoded.Capacity&(encoded.Capacity-1) != 0 {
+		return errors.New("hashmap: invalid encoded capacity")
+	}
+
+	m.startWrite()
+	defer m.endWrite()
+	if m.hashFunc == nil {
+		m.hashFunc = hasher.GetHashFunc[TKey]()
+		m.hashSeed = hasher.GenerateSeed()
+	}
+	m.layout = encoded.Layout
+	m.loadFactor = encoded.LoadFactor
+	m.freeStorage(m.storage)
+	m.storage = nil
+	m.engine = nil
+	m.shared = false
+	m.initStorage(decodedCapacity(encoded.Capacity, len(encoded.Entries), encoded.LoadFactor))
+	m.length = 0
+	m.maxProbe = 0
+
+	for _, entry := range encoded.Entries {
+		m.set(entry.Key, m.hash(entry.Key), entry.Value)
+	}
+	if m.fingerprint != nil {
+		// Hooks are not called for decoded entries
+		m.fingerprint.sum = m.fingerprintSum(m.fingerprint.keyHash, m.fingerprint.valueHash)
+	}
+	return nil
+}
+
+// Get the capacity of a decoded hashmap: the encoded one, unless it exceeds both the capacity needed by the entries and
+// maxDecodedSpareCapacity. The needed capacity only depends on the number of entries, bounded by the payload size,
+// and on the load factor, which is at least minLoadFactor.
+func decodedCapacity(capacity, entries int, loadFactor float32) int {
+	needed := 1
+	for float64(needed) < math.Ceil(float64(entries)/float64(loadFactor)) {
+		needed *= 2
+	}
+	return min(capacity, max(needed, maxDecodedSpareCapacity))
+}
+
+// Encode the hashmap into a binary form, see GobEncode.
+//
+// Implements the encoding.BinaryMarshaler interface.
+func (m *Hashmap[TKey, TValue]) MarshalBinary() ([]byte, error) {
+	return m.GobEncode()
+}
+
+// Decode a binary form produced by MarshalBinary, see GobDecode.
+//
+// Implements the encoding.BinaryUnmarshaler interface.
+func (m *Hashmap[TKey, TValue]) UnmarshalBinary(data []byte) error {
+	return m.GobDecode(data)
+}
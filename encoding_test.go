@@ -0,0 +1,150 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"slices"
+	"testing"
+)
+
+func TestGobRoundTrip(t *testing.T) {
+	type container struct {
+		Name string
+		Map  *Hashmap[string, int]
+	}
+
+	m := New(
+		WithInitialCapacity[string, int](256),
+		WithMaxLoadPercentage[string, int](70),
+	)
+	m.Set("key1", 123)
+	m.Set("key2", 456)
+	m.Set("key3", 789)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(container{"test", m}); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+
+	var decoded container
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+
+	if decoded.Map.Len() != m.Len() {
+		t.Errorf("invalid length. expected=%d, got=%d", m.Len(), decoded.Map.Len())
+	}
+	for _, kv := range m.GetEntries() {
+		value, found := decoded.Map.TryGet(kv.Key)
+		if !found {
+			t.Errorf("key=%s not found", kv.Key)
+		}
+		if value != kv.Value {
+			t.Errorf("retrieved invalid value for key=%s. expected=%d, got=%d", kv.Key, kv.Value, value)
+		}
+	}
+	if decoded.Map.loadFactor != m.loadFactor {
+		t.Errorf("invalid load factor. expected=%f, got=%f", m.loadFactor, decoded.Map.loadFactor)
+	}
+	if len(decoded.Map.storage) != len(m.storage) {
+		t.Errorf("invalid capacity. expected=%d, got=%d", len(m.storage), len(decoded.Map.storage))
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	testCases := [][]KeyValue[string, int]{
+		{},
+		{{"key1", 123}},
+		{{"key1", 123}, {"key2", 456}, {"key3", 789}},
+	}
+	for _, tc := range testCases {
+		m := New[string, int]()
+		for _, kv := range tc {
+			m.Set(kv.Key, kv.Value)
+		}
+
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshaling failed: %v", err)
+		}
+
+		decoded := New[string, int]()
+		decoded.Set("stale", 1)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unmarshaling failed: %v", err)
+		}
+
+		entries := decoded.GetEntries()
+		slices.SortFunc(entries, func(a, b KeyValue[string, int]) int {
+			return a.Value - b.Value
+		})
+		if !slices.Equal(entries, tc) {
+			t.Errorf("invalid entries. expected=%v, got=%v", tc, entries)
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	m := New[string, int]()
+	if err := m.UnmarshalBinary([]byte("invalid")); err == nil {
+		t.Errorf("expected an error for invalid data")
+	}
+}
+
+// A payload requesting a huge capacity for a few entries must not allocate it.
+func TestGobDecodeHugeCapacity(t *testing.T) {
+	var buf bytes.Buffer
+	payload := encodedHashmap[string, int]{
+		LoadFactor: 0.5,
+		Capacity:   1 << 40,
+		Entries:    []KeyValue[string, int]{{"key1", 123}, {"key2", 456}},
+	}
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+
+	m := New[string, int]()
+	if err := m.GobDecode(buf.Bytes()); err != nil {
+		t.Fatalf("decoding failed: %v", err)
+	}
+	if len(m.storage) != maxDecodedSpareCapacity {
+		t.Errorf("invalid capacity. expected=%d, got=%d", maxDecodedSpareCapacity, len(m.storage))
+	}
+	if m.Get("key1") != 123 || m.Get("key2") != 456 || m.Len() != 2 {
+		t.Errorf("invalid entries: %v", m.GetEntries())
+	}
+}
+
+// A tiny load factor must not make the capacity needed by the entries huge.
+func TestGobDecodeTinyLoadFactor(t *testing.T) {
+	var buf bytes.Buffer
+	payload := encodedHashmap[string, int]{
+		LoadFactor: 1e-30,
+		Capacity:   1 << 40,
+		Entries:    []KeyValue[string, int]{{"key1", 123}},
+	}
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		t.Fatalf("encoding failed: %v", err)
+	}
+	m := New[string, int]()
+	if err := m.UnmarshalBinary(buf.Bytes()); err == nil {
+		t.Errorf("expected an error for load factor=%g", payload.LoadFactor)
+	}
+}
+
+// A hashmap built with the lowest load percentage must be decodable.
+func TestBinaryRoundTripMinLoadFactor(t *testing.T) {
+	m := New(WithMaxLoadPercentage[string, int](0))
+	m.Set("key1", 123)
+	data, err := m.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshaling failed: %v", err)
+	}
+	decoded := New[string, int]()
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshaling failed: %v", err)
+	}
+	if decoded.loadFactor != minLoadFactor || decoded.Get("key1") != 123 {
+		t.Errorf("invalid decoded hashmap. load factor=%f, entries=%v", decoded.loadFactor, decoded.GetEntries())
+	}
+}
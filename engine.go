@@ -0,0 +1,69 @@
+package hashmap
+
+import (
+	"unsafe"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Storage layout of a Hashmap.
+type Layout uint8
+
+const (
+	// Robin Hood open addressing, with linear probing (default).
+	RobinHood Layout = iota
+	// Swiss table: slots are split in groups of 8 with one control byte each, groups are probed 8 slots at a time.
+	Swiss
+	// Bucketized cuckoo hashing: lookups read at most 2 buckets of 4 slots, whatever the keys distribution.
+	Cuckoo
+	// Robin Hood hashing with a structure of arrays: slots metadata, keys and values are stored in separate arrays,
+	// so probing doesn't load values in the CPU cache.
+	RobinHoodSoA
+)
+
+// Alternative storage engine, used in place of the built-in Robin Hood storage when another layout is selected.
+//
+// Keys are hashed by the Hashmap. Engines also get its hash function, to relocate entries when growing.
+type engine[TKey comparable, TValue any] interface {
+	get(key TKey, hash uint64) (TValue, bool)
+	// Insert or update an entry, returns whether a new entry was inserted.
+	set(key TKey, hash uint64, value TValue) bool
+	// Remove an entry, returns whether it existed.
+	delete(key TKey, hash uint64) bool
+	clear()
+	// Number of slots currently allocated.
+	capacity() int
+	forEach(fn func(key TKey, value TValue))
+	// Copy the engine storage, the copy uses the given hash function.
+	clone(hash func(TKey) uint64) engine[TKey, TValue]
+}
+
+// Create the storage of the given capacity, according to the hashmap layout.
+func (m *Hashmap[TKey, TValue]) initStorage(capacity int) {
+	switch m.layout {
+	case Swiss:
+		m.engine = newSwissTable[TKey, TValue](capacity, m.hash)
+	case Cuckoo:
+		m.engine = newCuckooTable[TKey, TValue](capacity, m.hash, m.seededHash(hasher.GenerateSeed()))
+	case RobinHoodSoA:
+		m.engine = newSoATable[TKey, TValue](capacity, m.loadFactor, m.hash)
+	default:
+		m.storage = m.allocStorage(capacity)
+	}
+}
+
+// Get the number of slots currently allocated.
+func (m *Hashmap[TKey, TValue]) capacity() int {
+	if m.engine != nil {
+		return m.engine.capacity()
+	}
+	return len(m.storage)
+}
+
+// Get a hash function using the hashmap's hasher with another seed.
+func (m *Hashmap[TKey, TValue]) seededHash(seed uintptr) func(TKey) uint64 {
+	hashFunc := m.hashFunc
+	return func(key TKey) uint64 {
+		return uint64(hashFunc(uintptr(unsafe.Pointer(&key)), seed))
+	}
+}
@@ -0,0 +1,79 @@
+package hashmap
+
+import "github.com/valsov/hashmap/hasher"
+
+// Fingerprint maintained on each write, see WithIncrementalFingerprint.
+type fingerprintState[TKey, TValue any] struct {
+	keyHash   func(TKey) uint64
+	valueHash func(TValue) uint64
+	sum       uint64 // Sum of the entry hashes
+}
+
+// Compute a digest of the hashmap content, which doesn't depend on the entries placement, the layout or the seed.
+//
+// keyHash and valueHash must be stable hash functions, like the ones of the hasher package: hashmaps holding the same
+// entries then have the same fingerprint, across processes. The hashes of a key and its value are mixed into an entry
+// hash, and entry hashes are summed, which doesn't depend on their order. See WithIncrementalFingerprint to maintain it on writes.
+func (m *Hashmap[TKey, TValue]) Fingerprint(keyHash func(TKey) uint64, valueHash func(TValue) uint64) uint64 {
+	m.checkRead()
+	return finalizeFingerprint(m.fingerprintSum(keyHash, valueHash), m.length)
+}
+
+// Get the fingerprint maintained on writes, which is equal to Fingerprint called with the configured hash functions.
+//
+// It panics if the hashmap was not created with WithIncrementalFingerprint.
+func (m *Hashmap[TKey, TValue]) IncrementalFingerprint() uint64 {
+	if m.fingerprint == nil {
+		panic("hashmap: incremental fingerprint is not enabled")
+	}
+	return finalizeFingerprint(m.fingerprint.sum, m.length)
+}
+
+// Maintain the given fingerprint on writes.
+func (m *Hashmap[TKey, TValue]) enableFingerprint(state *fingerprintState[TKey, TValue]) {
+	m.fingerprint = state
+	m.hooks = append(m.hooks, &changeHook[TKey, TValue]{fn: state.update})
+}
+
+// Sum the hashes of all entries.
+func (m *Hashmap[TKey, TValue]) fingerprintSum(keyHash func(TKey) uint64, valueHash func(TValue) uint64) uint64 {
+	var sum uint64
+	if m.engine != nil {
+		m.engine.forEach(func(key TKey, value TValue) {
+			sum += entryHash(keyHash(key), valueHash(value))
+		})
+		return sum
+	}
+	for _, entry := range m.storage {
+		if entry.alive {
+			sum += entryHash(keyHash(entry.key), valueHash(entry.value))
+		}
+	}
+	return sum
+}
+
+// Update the fingerprint with a change, the hashes of replaced entries are subtracted from the sum.
+func (s *fingerprintState[TKey, TValue]) update(event Event[TKey, TValue]) {
+	switch event.Kind {
+	case EventSet:
+		keyHash := s.keyHash(event.Key)
+		if event.Existed {
+			s.sum -= entryHash(keyHash, s.valueHash(event.OldValue))
+		}
+		s.sum += entryHash(keyHash, s.valueHash(event.NewValue))
+	case EventDelete:
+		s.sum -= entryHash(s.keyHash(event.Key), s.valueHash(event.OldValue))
+	case EventClear:
+		s.sum = 0
+	}
+}
+
+// Mix the hashes of a key and its value, the value hash is seeded with the key hash so that they are not interchangeable.
+func entryHash(keyHash, valueHash uint64) uint64 {
+	return hasher.Sum64Uint64(valueHash, keyHash)
+}
+
+// Mix the sum of the entry hashes with the number of entries.
+func finalizeFingerprint(sum uint64, length int) uint64 {
+	return hasher.Sum64Uint64(sum, uint64(length))
+}
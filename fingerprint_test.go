@@ -0,0 +1,92 @@
+package hashmap
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+func hashInt(value int) uint64 {
+	return hasher.Sum64Uint64(uint64(value), 0)
+}
+
+func TestFingerprint(t *testing.T) {
+	// Same entries inserted in different orders, in every layout
+	var fingerprints []uint64
+	for i, l := range layouts {
+		m := New(WithLayout[int, int](l.layout), WithInitialCapacity[int, int](8))
+		rng := rand.New(rand.NewSource(int64(i)))
+		for _, key := range rng.Perm(1000) {
+			m.Set(key, key*2)
+		}
+		fingerprints = append(fingerprints, m.Fingerprint(hashInt, hashInt))
+	}
+	for i, fingerprint := range fingerprints {
+		if fingerprint != fingerprints[0] {
+			t.Errorf("invalid fingerprint for layout=%s. expected=%d, got=%d", layouts[i].name, fingerprints[0], fingerprint)
+		}
+	}
+
+	m := New[int, int]()
+	empty := m.Fingerprint(hashInt, hashInt)
+	m.Set(1, 2)
+	swapped := New[int, int]()
+	swapped.Set(2, 1)
+	if m.Fingerprint(hashInt, hashInt) == empty || m.Fingerprint(hashInt, hashInt) == swapped.Fingerprint(hashInt, hashInt) {
+		t.Error("fingerprints of different contents must differ")
+	}
+	m.Delete(1)
+	if m.Fingerprint(hashInt, hashInt) != empty {
+		t.Error("fingerprint must only depend on the content")
+	}
+}
+
+func TestIncrementalFingerprint(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[int, int](l.layout), WithIncrementalFingerprint[int, int](hashInt, hashInt))
+		rng := rand.New(rand.NewSource(1))
+		for i := range 10_000 {
+			key := rng.Intn(500)
+			switch op := rng.Intn(10); {
+			case op < 6:
+				m.Set(key, i)
+			case op < 9:
+				m.Delete(key)
+			default:
+				m.SetMany([]int{key, key + 1}, []int{i, i})
+			}
+			if i == 5_000 {
+				m.Clear()
+			}
+		}
+		tx := m.Begin()
+		tx.Set(1, 1)
+		tx.Delete(2)
+		tx.Rollback()
+
+		expected := m.Fingerprint(hashInt, hashInt)
+		if fingerprint := m.IncrementalFingerprint(); fingerprint != expected {
+			t.Errorf("invalid incremental fingerprint for layout=%s. expected=%d, got=%d", l.name, expected, fingerprint)
+		}
+
+		clone := m.Clone()
+		clone.Set(-1, -1)
+		if clone.IncrementalFingerprint() != clone.Fingerprint(hashInt, hashInt) || m.IncrementalFingerprint() != expected {
+			t.Errorf("invalid incremental fingerprint of clone for layout=%s", l.name)
+		}
+
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshaling failed: %v", err)
+		}
+		decoded := New(WithIncrementalFingerprint[int, int](hashInt, hashInt))
+		decoded.Set(-1, -1)
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unmarshaling failed: %v", err)
+		}
+		if decoded.IncrementalFingerprint() != expected {
+			t.Errorf("invalid incremental fingerprint after decoding for layout=%s", l.name)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package hashmap
+
+import (
+	"unsafe"
+
+	"github.com/valsov/hashmap/hasher"
+	"github.com/valsov/hashmap/internal/mph"
+)
+
+const maxFreezeAttempts = 16 // Number of seeds tried before giving up on building a perfect hash
+
+// Immutable map built from a Hashmap, with a minimal perfect hash function.
+//
+// Entries are stored in a slice of exactly Len() slots, and every lookup checks a single slot.
+// Since it is never modified, a FrozenMap is safe for concurrent reads.
+type FrozenMap[TKey comparable, TValue any] struct {
+	entries       []KeyValue[TKey, TValue]
+	displacements []uint32 // Perfect hash function parameters, see the mph package
+	hashFunc      func(uintptr, uintptr) uintptr
+	hashSeed      uintptr
+}
+
+// Build an immutable copy of the hashmap, optimized for lookups.
+//
+// The hashmap's hash function is used, it panics if it yields the same hash for distinct keys whatever the seed.
+func (m *Hashmap[TKey, TValue]) Freeze() *FrozenMap[TKey, TValue] {
+	entries := m.GetEntries()
+	hashes := make([]uint64, len(entries))
+
+	for range maxFreezeAttempts {
+		frozen := &FrozenMap[TKey, TValue]{
+			hashFunc: m.hashFunc,
+			hashSeed: hasher.GenerateSeed(),
+		}
+		for i, entry := range entries {
+			hashes[i] = frozen.hash(entry.Key)
+		}
+
+		displacements, ok := mph.Build(hashes)
+		if !ok {
+			continue
+		}
+		frozen.displacements = displacements
+		frozen.entries = make([]KeyValue[TKey, TValue], len(entries))
+		for i, entry := range entries {
+			frozen.entries[mph.Index(hashes[i], displacements, len(entries))] = entry
+		}
+		return frozen
+	}
+	panic("hashmap: unable to build a perfect hash function, the hash function yields collisions")
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *FrozenMap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (m *FrozenMap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	if len(m.entries) == 0 {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+
+	entry := &m.entries[mph.Index(m.hash(key), m.displacements, len(m.entries))]
+	if entry.Key == key {
+		return entry.Value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+// Get the number of entries stored in the map.
+func (m *FrozenMap[TKey, TValue]) Len() int {
+	return len(m.entries)
+}
+
+// Get all entries stored in the map.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *FrozenMap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	entries := make([]KeyValue[TKey, TValue], len(m.entries))
+	copy(entries, m.entries)
+	return entries
+}
+
+// Compute the hash of the given key.
+//
+// It is scrambled, the perfect hash function needs all 64 bits while hashFunc only produces 32 bits on some platforms.
+func (m *FrozenMap[TKey, TValue]) hash(key TKey) uint64 {
+	return mph.Mix(uint64(m.hashFunc(uintptr(unsafe.Pointer(&key)), m.hashSeed)))
+}
@@ -0,0 +1,52 @@
+package hashmap
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	for _, entriesCount := range []int{0, 1, 2, 100, 10_000} {
+		m := New[string, int]()
+		for i := range entriesCount {
+			m.Set(strconv.Itoa(i), i)
+		}
+
+		frozen := m.Freeze()
+		if frozen.Len() != entriesCount {
+			t.Errorf("invalid length. expected=%d, got=%d", entriesCount, frozen.Len())
+		}
+		for i := range entriesCount {
+			key := strconv.Itoa(i)
+			value, found := frozen.TryGet(key)
+			if !found {
+				t.Errorf("key=%s not found", key)
+			}
+			if value != i {
+				t.Errorf("retrieved invalid value for key=%s. expected=%d, got=%d", key, i, value)
+			}
+		}
+		if value, found := frozen.TryGet("notfound"); found || value != 0 {
+			t.Errorf("key=notfound was found")
+		}
+		if len(frozen.GetEntries()) != entriesCount {
+			t.Errorf("invalid entries length. expected=%d, got=%d", entriesCount, len(frozen.GetEntries()))
+		}
+	}
+}
+
+func TestFreezeIsolation(t *testing.T) {
+	m := New[string, int]()
+	m.Set("key1", 123)
+	frozen := m.Freeze()
+
+	m.Set("key1", 456)
+	m.Set("key2", 789)
+
+	if value := frozen.Get("key1"); value != 123 {
+		t.Errorf("retrieved invalid value for key=key1. expected=123, got=%d", value)
+	}
+	if _, found := frozen.TryGet("key2"); found {
+		t.Errorf("key=key2 was found")
+	}
+}
@@ -0,0 +1,61 @@
+package hashmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+func TestHashedSharedSeed(t *testing.T) {
+	seed := hasher.GenerateSeed()
+	maps := []*Hashmap[string, int]{
+		New(WithSeed[string, int](seed)),
+		New(WithSeed[string, int](seed), WithLayout[string, int](Swiss)),
+		New(WithSeed[string, int](seed), WithLayout[string, int](Cuckoo)),
+		New(WithSeed[string, int](seed), WithLayout[string, int](RobinHoodSoA)),
+	}
+
+	for i := range 1000 {
+		key := fmt.Sprint(i)
+		hash := maps[0].Hash(key)
+		for _, m := range maps {
+			m.SetHashed(key, hash, i)
+		}
+	}
+	for i := range 1000 {
+		key := fmt.Sprint(i)
+		hash := maps[0].Hash(key)
+		for _, m := range maps {
+			if value := m.GetHashed(key, hash); value != i {
+				t.Errorf("retrieved invalid value for key=%s. expected=%d, got=%d", key, i, value)
+			}
+			if value := m.Get(key); value != i {
+				t.Errorf("retrieved invalid value for key=%s. expected=%d, got=%d", key, i, value)
+			}
+			if i%2 == 0 {
+				m.DeleteHashed(key, hash)
+			}
+		}
+	}
+	for _, m := range maps {
+		if m.Len() != 500 {
+			t.Errorf("invalid length. expected=500, got=%d", m.Len())
+		}
+		if _, found := m.TryGetHashed("0", maps[0].Hash("0")); found {
+			t.Error("deleted key found")
+		}
+	}
+}
+
+func TestHashZeroValue(t *testing.T) {
+	var m Hashmap[string, int]
+	hash := m.Hash("key")
+	if hash != m.Hash("key") {
+		t.Fatal("hash is not stable")
+	}
+	m.SetHashed("key", hash, 1)
+	if value := m.Get("key"); value != 1 {
+		t.Errorf("retrieved invalid value. expected=1, got=%d", value)
+	}
+}
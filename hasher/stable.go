@@ -0,0 +1,57 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"unsafe"
+)
+
+// Stable hashing primes
+const (
+	stablePrime1 uint64 = 0xa0761d6478bd642f
+	stablePrime2 uint64 = 0xe7037ed1a0b428db
+	stablePrime3 uint64 = 0x8ebc6af09c88c6e3
+)
+
+// Compute a stable hash of the given bytes.
+//
+// Unlike the functions returned by GetHashFunc, the result only depends on the input bytes and the seed:
+// it is identical across processes, machines and Go versions, so it can be persisted.
+func Sum64(data []byte, seed uint64) uint64 {
+	h := seed ^ stablePrime1
+	length := uint64(len(data))
+	for len(data) > 16 {
+		h = mix(binary.LittleEndian.Uint64(data)^stablePrime2, binary.LittleEndian.Uint64(data[8:])^h)
+		data = data[16:]
+	}
+
+	// Remaining 0 to 16 bytes, overlapping reads are used to avoid a byte loop
+	var a, b uint64
+	switch {
+	case len(data) >= 8:
+		a = binary.LittleEndian.Uint64(data)
+		b = binary.LittleEndian.Uint64(data[len(data)-8:])
+	case len(data) >= 4:
+		a = uint64(binary.LittleEndian.Uint32(data))
+		b = uint64(binary.LittleEndian.Uint32(data[len(data)-4:]))
+	case len(data) > 0:
+		a = uint64(data[0])<<16 | uint64(data[len(data)>>1])<<8 | uint64(data[len(data)-1])
+	}
+	return mix(stablePrime3^length, mix(a^stablePrime2, b^h))
+}
+
+// Compute a stable hash of the given string, see Sum64.
+func Sum64String(s string, seed uint64) uint64 {
+	return Sum64(unsafe.Slice(unsafe.StringData(s), len(s)), seed)
+}
+
+// Compute a stable hash of the given integer, see Sum64.
+func Sum64Uint64(value, seed uint64) uint64 {
+	return mix(stablePrime3^8, mix(value^stablePrime2, seed^stablePrime1))
+}
+
+// Multiply both values and fold the 128 bits result.
+func mix(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
@@ -0,0 +1,52 @@
+package hasher
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSum64Stability(t *testing.T) {
+	// Stable hashes may be persisted, these values must never change
+	testCases := []struct {
+		value    string
+		seed     uint64
+		expected uint64
+	}{
+		{"", 0, 0xa5506ff52926ac13},
+		{"", 42, 0x6b7f8de6747b3b8f},
+		{"a", 0, 0x37c0ab888779f5ba},
+		{"a", 42, 0xf5c0a2aeafc563e7},
+		{"abc", 0, 0xafb283181d8cea3f},
+		{"abcd", 0, 0x43813bda811fb9b0},
+		{"hashmap", 0, 0x26e294c4548081e8},
+		{"hashmap", 42, 0x677b6111eadc7d6c},
+		{"0123456789abcdef", 0, 0xe6093a35da64e5d3},
+		{"the quick brown fox jumps over the lazy dog", 0, 0x5767e47bca11d9d2},
+		{"the quick brown fox jumps over the lazy dog", 42, 0x36946fa74d7801bd},
+	}
+	for _, tc := range testCases {
+		hash := Sum64String(tc.value, tc.seed)
+		if hash != tc.expected {
+			t.Errorf("unstable hash for value=%q seed=%d. expected=%#x, got=%#x", tc.value, tc.seed, tc.expected, hash)
+		}
+		if bytesHash := Sum64([]byte(tc.value), tc.seed); bytesHash != hash {
+			t.Errorf("string and bytes hashes differ for value=%q. bytes=%#x, string=%#x", tc.value, bytesHash, hash)
+		}
+	}
+
+	if hash := Sum64Uint64(12345, 42); hash != 0xca2c34f5e99d78 {
+		t.Errorf("unstable integer hash. expected=%#x, got=%#x", uint64(0xca2c34f5e99d78), hash)
+	}
+}
+
+func TestSum64Collisions(t *testing.T) {
+	hashes := map[uint64]string{}
+	for i := range 100_000 {
+		value := strconv.Itoa(i)
+		hash := Sum64String(value, 0)
+		if existing, found := hashes[hash]; found {
+			t.Fatalf("hash collision detected. value1=%s, value2=%s", existing, value)
+		}
+		hashes[hash] = value
+	}
+}
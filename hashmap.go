@@ -8,6 +8,7 @@ import (
 
 const defaultInitialCapacity uint = 128 // Power of 2
 const defaultLoadFactor float32 = 0.5
+const minLoadFactor float32 = 0.01 // See WithMaxLoadPercentage
 
 // Key value pair
 type KeyValue[TKey, TValue any] struct {
@@ -24,50 +25,247 @@ type mapEntry[TKey, TValue any] struct {
 
 // Hashmap struct for fast data lookup
 //
+// The zero value is an empty hashmap with the default configuration, ready to use. Its storage is allocated on the first Set.
 // The capacity of the hashmap must be a power of 2. This allows to do: hash & (cap - 1) to compute indexes.
 // This way, the use of modulo operator is avoided (which is a much slower operation compared to bitwise AND).
 type Hashmap[TKey comparable, TValue any] struct {
-	storage    []mapEntry[TKey, TValue]
-	length     int     // Number of entries in the hashmap
-	loadFactor float32 // Load at which a storage growth will take place
-	maxProbe   int     // The maximum number of slots a key search should check, this is the max distance an entry was placed from its ideal index
-	hashFunc   func(uintptr, uintptr) uintptr
-	hashSeed   uintptr
+	storage         []mapEntry[TKey, TValue]
+	engine          engine[TKey, TValue] // Storage engine of non Robin Hood layouts, storage is unused when set
+	layout          Layout
+	initialCapacity uint
+	length          int     // Number of entries in the hashmap
+	loadFactor      float32 // Load at which a storage growth will take place
+	maxProbe        int     // The maximum number of slots a key search should check, this is the max distance an entry was placed from its ideal index
+	hashFunc        func(uintptr, uintptr) uintptr
+	hashSeed        uintptr
+	offHeap         bool                            // Whether storage is allocated outside of the Go heap
+	writing         bool                            // Set during writes, to detect concurrent misuse
+	hooks           []*changeHook[TKey, TValue]     // See OnChange
+	shared          bool                            // Whether the storage is shared with a snapshot, it must be copied before writing it
+	fingerprint     *fingerprintState[TKey, TValue] // See WithIncrementalFingerprint
 }
 
 // Instanciate a new hashmap with a custom key bytes reader function.
 func New[TKey comparable, TValue any](config ...HashMapConfig[TKey, TValue]) *Hashmap[TKey, TValue] {
 	m := &Hashmap[TKey, TValue]{
-		loadFactor: defaultLoadFactor,
-		hashSeed:   hasher.GenerateSeed(),
+		loadFactor:      defaultLoadFactor,
+		initialCapacity: defaultInitialCapacity,
+		hashSeed:        hasher.GenerateSeed(),
 	}
 	for _, configFunc := range config {
 		configFunc(m)
 	}
 
-	if m.storage == nil {
-		m.storage = make([]mapEntry[TKey, TValue], defaultInitialCapacity)
-	}
 	if m.hashFunc == nil {
 		m.hashFunc = hasher.GetHashFunc[TKey]()
 	}
+	m.offHeap = m.offHeap && m.canUseOffHeap()
+	m.initStorage(int(m.initialCapacity))
 
 	return m
 }
 
 // Get the value associated with the given key. A default value is returned if the key doesn't exist.
 func (m *Hashmap[TKey, TValue]) Get(key TKey) TValue {
-	index, found := m.tryGetKeyIndex(key)
-	if found {
-		return m.storage[index].value
+	if m.length == 0 {
+		var zeroEntry TValue
+		return zeroEntry
 	}
-	var zeroEntry TValue
-	return zeroEntry
+	value, _ := m.tryGet(key, m.hash(key))
+	return value
 }
 
 // Try to get the value associated with the given key.
 func (m *Hashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
-	index, found := m.tryGetKeyIndex(key)
+	if m.length == 0 {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+	return m.tryGet(key, m.hash(key))
+}
+
+// Insert or update the given value at the given key.
+func (m *Hashmap[TKey, TValue]) Set(key TKey, value TValue) {
+	// Hashing may panic for interface keys, the write starts once it's done so that the hashmap stays usable
+	if len(m.storage) == 0 && m.engine == nil {
+		m.lazyInit()
+	}
+	hash := m.hash(key)
+	m.startWrite()
+	old, existed := m.previous(key, hash)
+	m.set(key, hash, value)
+	m.endWrite()
+	if m.hooks != nil {
+		m.notify(Event[TKey, TValue]{Kind: EventSet, Key: key, OldValue: old, Existed: existed, NewValue: value})
+	}
+}
+
+// Remove the entry with the given key from the hashmap.
+func (m *Hashmap[TKey, TValue]) Delete(key TKey) {
+	if m.length == 0 {
+		return
+	}
+	hash := m.hash(key)
+	m.startWrite()
+	old, existed := m.previous(key, hash)
+	m.delete(key, hash)
+	m.endWrite()
+	if existed {
+		m.notify(Event[TKey, TValue]{Kind: EventDelete, Key: key, OldValue: old, Existed: true})
+	}
+}
+
+// Compute the hash of the given key, to be used with the Hashed methods variants.
+//
+// Hashmaps with the same key type and seed (see WithSeed), using the default hash function, compute the same hashes.
+// A zero value hashmap draws a random seed on its first Hash call or write, the same way: hashes passed to it must be
+// computed by its own Hash method.
+func (m *Hashmap[TKey, TValue]) Hash(key TKey) uint64 {
+	if m.hashFunc == nil {
+		m.initHasher()
+	}
+	return m.hash(key)
+}
+
+// Get the value associated with the given key, whose hash was computed by Hash. See Get.
+func (m *Hashmap[TKey, TValue]) GetHashed(key TKey, hash uint64) TValue {
+	value, _ := m.TryGetHashed(key, hash)
+	return value
+}
+
+// Try to get the value associated with the given key, whose hash was computed by Hash. See TryGet.
+func (m *Hashmap[TKey, TValue]) TryGetHashed(key TKey, hash uint64) (TValue, bool) {
+	if m.length == 0 {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+	m.checkHash(key, hash)
+	return m.tryGet(key, hash)
+}
+
+// Insert or update the given value at the given key, whose hash was computed by Hash. See Set.
+func (m *Hashmap[TKey, TValue]) SetHashed(key TKey, hash uint64, value TValue) {
+	if len(m.storage) == 0 && m.engine == nil {
+		m.lazyInit()
+	}
+	m.checkHash(key, hash)
+	m.startWrite()
+	old, existed := m.previous(key, hash)
+	m.set(key, hash, value)
+	m.endWrite()
+	if m.hooks != nil {
+		m.notify(Event[TKey, TValue]{Kind: EventSet, Key: key, OldValue: old, Existed: existed, NewValue: value})
+	}
+}
+
+// Remove the entry with the given key, whose hash was computed by Hash. See Delete.
+func (m *Hashmap[TKey, TValue]) DeleteHashed(key TKey, hash uint64) {
+	if m.length == 0 {
+		return
+	}
+	m.checkHash(key, hash)
+	m.startWrite()
+	old, existed := m.previous(key, hash)
+	m.delete(key, hash)
+	m.endWrite()
+	if existed {
+		m.notify(Event[TKey, TValue]{Kind: EventDelete, Key: key, OldValue: old, Existed: true})
+	}
+}
+
+// Remove all entries from the hashmap.
+func (m *Hashmap[TKey, TValue]) Clear() {
+	m.startWrite()
+	m.clearStorage()
+	m.endWrite()
+	m.notify(Event[TKey, TValue]{Kind: EventClear})
+}
+
+// Remove all entries, keeping the storage capacity.
+func (m *Hashmap[TKey, TValue]) clearStorage() {
+	if m.shared {
+		// Allocate a new storage rather than copying the shared one
+		capacity := m.capacity()
+		m.shared = false
+		m.engine = nil
+		m.initStorage(capacity)
+		m.length = 0
+		m.maxProbe = 0
+		return
+	}
+	if m.engine != nil {
+		m.engine.clear()
+		m.length = 0
+		return
+	}
+
+	if m.offHeap {
+		clear(m.storage)
+	} else {
+		m.storage = make([]mapEntry[TKey, TValue], len(m.storage))
+	}
+	m.length = 0
+	m.maxProbe = 0
+}
+
+// Release the storage of the hashmap, this is only required when off-heap storage is used, see WithOffHeapStorage.
+//
+// The hashmap must not be used after being closed.
+func (m *Hashmap[TKey, TValue]) Close() error {
+	m.startWrite()
+	defer m.endWrite()
+	storage := m.storage
+	m.storage = nil
+	m.engine = nil
+	m.length = 0
+	m.maxProbe = 0
+	if m.fingerprint != nil {
+		m.fingerprint.sum = 0
+	}
+	return m.freeStorage(storage)
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *Hashmap[TKey, TValue]) Len() int {
+	return int(m.length)
+}
+
+// Get all entries stored in the hashmap.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *Hashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	m.checkRead()
+	entries := make([]KeyValue[TKey, TValue], m.length)
+	index := 0
+	if m.engine != nil {
+		m.engine.forEach(func(key TKey, value TValue) {
+			entries[index] = KeyValue[TKey, TValue]{
+				Key:   key,
+				Value: value,
+			}
+			index++
+		})
+		return entries
+	}
+	for _, entry := range m.storage {
+		if entry.alive {
+			entries[index] = KeyValue[TKey, TValue]{
+				Key:   entry.key,
+				Value: entry.value,
+			}
+			index++
+		}
+	}
+	return entries
+}
+
+// Look up the given key with its hash.
+func (m *Hashmap[TKey, TValue]) tryGet(key TKey, hash uint64) (TValue, bool) {
+	m.checkRead()
+	if m.engine != nil {
+		return m.engine.get(key, hash)
+	}
+	index, found := m.tryGetKeyIndex(key, hash)
 	if found {
 		return m.storage[index].value, true
 	}
@@ -75,23 +273,27 @@ func (m *Hashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
 	return zeroEntry, false
 }
 
-// Insert or update the given value at the given key.
-func (m *Hashmap[TKey, TValue]) Set(key TKey, value TValue) {
+// Insert or update the given key with its hash.
+func (m *Hashmap[TKey, TValue]) set(key TKey, hash uint64, value TValue) {
+	if m.shared {
+		m.unshare()
+	}
+	if m.engine != nil {
+		if m.engine.set(key, hash, value) {
+			m.length++
+		}
+		return
+	}
+
 	if float64(m.length) >= float64(len(m.storage))*float64(m.loadFactor) {
 		m.grow()
 	}
 	m.length++
 
 	// Find suitable slot
-	index := m.getIdealKeyIndex(key)
+	index := int(hash & uint64(len(m.storage)-1))
 	var distance int
 	for {
-		if m.storage[index].key == key {
-			m.storage[index].value = value
-			m.length-- // Replacement
-			return
-		}
-
 		if !m.storage[index].alive {
 			m.storage[index].key = key
 			m.storage[index].value = value
@@ -101,6 +303,12 @@ func (m *Hashmap[TKey, TValue]) Set(key TKey, value TValue) {
 			return
 		}
 
+		if m.storage[index].key == key {
+			m.storage[index].value = value
+			m.length-- // Replacement
+			return
+		}
+
 		curSlotIdealIndex := m.getIdealKeyIndex(m.storage[index].key)
 		curSlotDistance := (index + len(m.storage) - curSlotIdealIndex) & (len(m.storage) - 1)
 		if distance > curSlotDistance {
@@ -116,10 +324,20 @@ func (m *Hashmap[TKey, TValue]) Set(key TKey, value TValue) {
 	}
 }
 
-// Remove the entry with the given key from the hashmap.
-func (m *Hashmap[TKey, TValue]) Delete(key TKey) {
+// Remove the given key with its hash.
+func (m *Hashmap[TKey, TValue]) delete(key TKey, hash uint64) {
+	if m.shared {
+		m.unshare()
+	}
+	if m.engine != nil {
+		if m.engine.delete(key, hash) {
+			m.length--
+		}
+		return
+	}
+
 	// Find entry
-	index, found := m.tryGetKeyIndex(key)
+	index, found := m.tryGetKeyIndex(key, hash)
 	if !found {
 		return
 	}
@@ -152,49 +370,39 @@ func (m *Hashmap[TKey, TValue]) Delete(key TKey) {
 	}
 }
 
-// Remove all entries from the hashmap.
-func (m *Hashmap[TKey, TValue]) Clear() {
-	m.storage = make([]mapEntry[TKey, TValue], len(m.storage))
-	m.length = 0
-	m.maxProbe = 0
-}
-
-// Get the number of entries stored in the hashmap.
-func (m *Hashmap[TKey, TValue]) Len() int {
-	return int(m.length)
+// Initialize the storage of a zero value hashmap, unset properties get their default value.
+func (m *Hashmap[TKey, TValue]) lazyInit() {
+	if m.loadFactor == 0 {
+		m.loadFactor = defaultLoadFactor
+	}
+	if m.initialCapacity == 0 {
+		m.initialCapacity = defaultInitialCapacity
+	}
+	if m.hashFunc == nil {
+		m.initHasher()
+	}
+	m.initStorage(int(m.initialCapacity))
 }
 
-// Get all entries stored in the hashmap.
-//
-// The slice ordering is not guaranteed to be the insertion order.
-func (m *Hashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
-	entries := make([]KeyValue[TKey, TValue], m.length)
-	index := 0
-	for _, entry := range m.storage {
-		if entry.alive {
-			entries[index] = KeyValue[TKey, TValue]{
-				Key:   entry.key,
-				Value: entry.value,
-			}
-			index++
-		}
-	}
-	return entries
+// Set the default hash function of a zero value hashmap, with a random seed.
+func (m *Hashmap[TKey, TValue]) initHasher() {
+	m.hashFunc = hasher.GetHashFunc[TKey]()
+	m.hashSeed = hasher.GenerateSeed()
 }
 
 // Main lookup function, try to find the index of the given key.
-func (m *Hashmap[TKey, TValue]) tryGetKeyIndex(key TKey) (int, bool) {
-	index := m.getIdealKeyIndex(key)
+func (m *Hashmap[TKey, TValue]) tryGetKeyIndex(key TKey, hash uint64) (int, bool) {
+	index := int(hash & uint64(len(m.storage)-1))
 	// The value can only be located within a range of m.maxProbe from its ideal index
 	for range m.maxProbe + 1 {
-		if m.storage[index].key == key {
-			return index, true
-		}
-
 		if !m.storage[index].alive {
 			return 0, false
 		}
 
+		if m.storage[index].key == key {
+			return index, true
+		}
+
 		index = (index + 1) & (len(m.storage) - 1)
 	}
 	return 0, false
@@ -202,8 +410,12 @@ func (m *Hashmap[TKey, TValue]) tryGetKeyIndex(key TKey) (int, bool) {
 
 // Compute the index at which the given key should be located.
 func (m *Hashmap[TKey, TValue]) getIdealKeyIndex(key TKey) int {
-	hash := uint64(m.hashFunc(uintptr(unsafe.Pointer(&key)), m.hashSeed))
-	return int(hash & uint64(len(m.storage)-1))
+	return int(m.hash(key) & uint64(len(m.storage)-1))
+}
+
+// Compute the hash of the given key.
+func (m *Hashmap[TKey, TValue]) hash(key TKey) uint64 {
+	return uint64(m.hashFunc(uintptr(unsafe.Pointer(&key)), m.hashSeed))
 }
 
 // Set the slot's value to the default, dead slot.
@@ -216,12 +428,46 @@ func (m *Hashmap[TKey, TValue]) emptySlot(index int) {
 // Entries from the previous storage are put into the new storage.
 func (m *Hashmap[TKey, TValue]) grow() {
 	oldStorage := m.storage
-	m.storage = make([]mapEntry[TKey, TValue], len(m.storage)*2)
+	m.storage = m.allocStorage(len(m.storage) * 2)
 	m.length = 0 // Reset length, it will be set by m.Set()
 
 	for _, entry := range oldStorage {
 		if entry.alive {
-			m.Set(entry.key, entry.value)
+			m.set(entry.key, m.hash(entry.key), entry.value)
+		}
+	}
+	m.freeStorage(oldStorage)
+	m.checkWriting() // Growing takes long, another writer is likely to be caught
+}
+
+// Mark the beginning of a write, panics if another write is in progress.
+func (m *Hashmap[TKey, TValue]) startWrite() {
+	if misuseChecks {
+		if m.writing {
+			panic("hashmap: concurrent hashmap writes")
 		}
+		m.writing = true
+	}
+}
+
+// Mark the end of a write, panics if another write ended meanwhile.
+func (m *Hashmap[TKey, TValue]) endWrite() {
+	if misuseChecks {
+		m.checkWriting()
+		m.writing = false
+	}
+}
+
+// Panic if the write in progress was ended by another one.
+func (m *Hashmap[TKey, TValue]) checkWriting() {
+	if misuseChecks && !m.writing {
+		panic("hashmap: concurrent hashmap writes")
+	}
+}
+
+// Panic if a write is in progress.
+func (m *Hashmap[TKey, TValue]) checkRead() {
+	if misuseChecks && m.writing {
+		panic("hashmap: concurrent hashmap read and hashmap write")
 	}
 }
@@ -1,6 +1,7 @@
 package hashmap
 
 import (
+	"fmt"
 	"slices"
 	"testing"
 )
@@ -281,3 +282,38 @@ func TestGetEntries(t *testing.T) {
 		}
 	}
 }
+
+func TestZeroValue(t *testing.T) {
+	var m Hashmap[string, int]
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = m.Get("key")
+		_, _ = m.TryGet("key")
+		m.Delete("key")
+		_ = m.Len()
+	})
+	if allocs != 0 {
+		t.Errorf("invalid allocations count for reads on a zero value. expected=0, got=%v", allocs)
+	}
+	if _, err := m.MarshalBinary(); err != nil {
+		t.Errorf("marshaling a zero value failed: %v", err)
+	}
+	m.Clear()
+
+	var embedding struct {
+		entries Hashmap[string, int]
+	}
+	for _, zero := range []*Hashmap[string, int]{&m, &embedding.entries} {
+		for i := range 1000 {
+			zero.Set(fmt.Sprint(i), i)
+		}
+		zero.Delete("0")
+		if zero.Len() != 999 {
+			t.Errorf("invalid length. expected=999, got=%d", zero.Len())
+		}
+		for i := 1; i < 1000; i++ {
+			if value := zero.Get(fmt.Sprint(i)); value != i {
+				t.Errorf("retrieved invalid value for key=%d. expected=%d, got=%d", i, i, value)
+			}
+		}
+	}
+}
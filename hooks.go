@@ -0,0 +1,47 @@
+package hashmap
+
+import "slices"
+
+type changeHook[TKey, TValue any] struct {
+	fn func(Event[TKey, TValue])
+}
+
+// Register a hook, called synchronously after each change of the hashmap: Set, Delete of an existing key and Clear.
+// Returns a function removing the hook.
+//
+// Hooks are called once the change is complete, they can read and modify the hashmap.
+// Previous values are looked up before changes only when hooks are registered.
+func (m *Hashmap[TKey, TValue]) OnChange(hook func(Event[TKey, TValue])) func() {
+	registered := &changeHook[TKey, TValue]{fn: hook}
+	m.hooks = append(slices.Clip(m.hooks), registered)
+	return func() {
+		// The slice is not modified in place, hooks being called are not skipped
+		m.hooks = slices.DeleteFunc(slices.Clone(m.hooks), func(other *changeHook[TKey, TValue]) bool {
+			return other == registered
+		})
+	}
+}
+
+// Get the value of a key before changing it, when hooks are registered.
+func (m *Hashmap[TKey, TValue]) previous(key TKey, hash uint64) (TValue, bool) {
+	if len(m.hooks) == 0 || m.length == 0 {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+	if m.engine != nil {
+		return m.engine.get(key, hash)
+	}
+	index, found := m.tryGetKeyIndex(key, hash)
+	if !found {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+	return m.storage[index].value, true
+}
+
+// Call the registered hooks with the given change.
+func (m *Hashmap[TKey, TValue]) notify(event Event[TKey, TValue]) {
+	for _, hook := range m.hooks {
+		hook.fn(event)
+	}
+}
@@ -0,0 +1,42 @@
+package hashmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnChange(t *testing.T) {
+	for _, l := range layouts {
+		var m Hashmap[string, int]
+		m.layout = l.layout
+		var events []Event[string, int]
+		remove := m.OnChange(func(event Event[string, int]) {
+			events = append(events, event)
+			if event.Kind == EventSet && event.Key == "a" {
+				m.Set("copy", m.Get("a")) // Hooks can use the hashmap
+			}
+		})
+
+		m.Set("a", 1)
+		m.Set("a", 2)
+		m.Delete("missing")
+		m.DeleteHashed("a", m.Hash("a"))
+		m.SetMany([]string{"b"}, []int{3})
+		m.Clear()
+		remove()
+		m.Set("c", 4)
+
+		expected := []Event[string, int]{
+			{Kind: EventSet, Key: "a", NewValue: 1},
+			{Kind: EventSet, Key: "copy", NewValue: 1},
+			{Kind: EventSet, Key: "a", OldValue: 1, Existed: true, NewValue: 2},
+			{Kind: EventSet, Key: "copy", OldValue: 1, Existed: true, NewValue: 2},
+			{Kind: EventDelete, Key: "a", OldValue: 2, Existed: true},
+			{Kind: EventSet, Key: "b", NewValue: 3},
+			{Kind: EventClear},
+		}
+		if !reflect.DeepEqual(events, expected) {
+			t.Errorf("invalid events for layout=%s.\nexpected=%+v\ngot=%+v", l.name, expected, events)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package mph builds minimal perfect hash functions with the CHD algorithm
+// (compress, hash and displace, see http://cmph.sourceforge.net/papers/esa09.pdf).
+//
+// Keys are hashed into small buckets, and a displacement is searched for each bucket so that all of its keys
+// land on free slots. A key's slot is then computed from its hash and its bucket's displacement,
+// which maps n distinct hashes onto [0, n) without collision.
+package mph
+
+import "slices"
+
+const (
+	bucketSize  = 4       // Average number of keys per bucket
+	maxAttempts = 1 << 24 // Maximum number of displacements tried for a single bucket
+	golden      = 0x9e3779b97f4a7c15
+)
+
+// Compute the displacements of a minimal perfect hash function for the given hashes.
+//
+// false is returned if it could not be built, this is always the case when hashes contain duplicates.
+// The hashes should then be computed again with another seed.
+func Build(hashes []uint64) ([]uint32, bool) {
+	n := len(hashes)
+	if n == 0 {
+		return nil, true
+	}
+	sorted := slices.Clone(hashes)
+	slices.Sort(sorted)
+	if len(slices.Compact(sorted)) != n {
+		return nil, false
+	}
+
+	// Group hashes by bucket, largest buckets are placed first, while most slots are still free
+	displacements := make([]uint32, BucketCount(n))
+	buckets := make([][]uint64, len(displacements))
+	for _, hash := range hashes {
+		bucket := Bucket(hash, len(displacements))
+		buckets[bucket] = append(buckets[bucket], hash)
+	}
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return len(buckets[b]) - len(buckets[a])
+	})
+
+	taken := make([]bool, n)
+	slots := make([]int, 0, 2*bucketSize)
+	for _, bucket := range order {
+		if len(buckets[bucket]) == 0 {
+			break
+		}
+
+		found := false
+		for displacement := range uint32(maxAttempts) {
+			slots = slots[:0]
+			for _, hash := range buckets[bucket] {
+				slot := Slot(hash, displacement, n)
+				if taken[slot] || slices.Contains(slots, slot) {
+					break
+				}
+				slots = append(slots, slot)
+			}
+			if len(slots) == len(buckets[bucket]) {
+				for _, slot := range slots {
+					taken[slot] = true
+				}
+				displacements[bucket] = displacement
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return displacements, true
+}
+
+// Get the slot of the given hash, in [0, n).
+func Index(hash uint64, displacements []uint32, n int) int {
+	return Slot(hash, displacements[Bucket(hash, len(displacements))], n)
+}
+
+// Get the number of buckets used for n hashes.
+func BucketCount(n int) int {
+	return max(1, (n+bucketSize-1)/bucketSize)
+}
+
+// Get the bucket of the given hash, in [0, bucketCount).
+func Bucket(hash uint64, bucketCount int) int {
+	return int((hash >> 32) * uint64(bucketCount) >> 32)
+}
+
+// Get the slot of the given hash for a displacement, in [0, n).
+func Slot(hash uint64, displacement uint32, n int) int {
+	return int(uint64(uint32(Mix(hash+uint64(displacement)*golden))) * uint64(n) >> 32)
+}
+
+// Scramble the bits of a hash (murmur3 finalizer).
+func Mix(hash uint64) uint64 {
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
@@ -0,0 +1,39 @@
+package mph
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 10, 1000, 100_000} {
+		hashes := make([]uint64, n)
+		for i := range hashes {
+			hashes[i] = rand.Uint64()
+		}
+
+		displacements, ok := Build(hashes)
+		if !ok {
+			t.Errorf("build failed. n=%d", n)
+			continue
+		}
+
+		seen := make([]bool, n)
+		for _, hash := range hashes {
+			index := Index(hash, displacements, n)
+			if index < 0 || index >= n {
+				t.Fatalf("index out of range. n=%d, index=%d", n, index)
+			}
+			if seen[index] {
+				t.Fatalf("index collision. n=%d, index=%d", n, index)
+			}
+			seen[index] = true
+		}
+	}
+}
+
+func TestBuildDuplicates(t *testing.T) {
+	if _, ok := Build([]uint64{1, 2, 3, 2}); ok {
+		t.Errorf("build succeeded with duplicate hashes")
+	}
+}
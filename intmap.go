@@ -0,0 +1,190 @@
+package hashmap
+
+import "math/bits"
+
+const fibonacciMultiplier = 11400714819323198485 // 2^64 / golden ratio
+
+// Integer key types supported by IntHashmap.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Hashmap specialized for integer keys.
+//
+// Keys are hashed inline with Fibonacci hashing: the key is multiplied by 2^64 / golden ratio, and the top bits
+// of the product give the index. This spreads both dense and sparse keys evenly, without calling a hash function.
+type IntHashmap[TKey Integer, TValue any] struct {
+	storage    []mapEntry[TKey, TValue]
+	shift      uint // 64 - log2(len(storage)), keeps the top bits of the product
+	length     int
+	loadFactor float32
+	seed       uint64
+}
+
+// Instanciate a new integer keyed hashmap.
+//
+// The load percentage, initial capacity and seed configurations are supported, other ones are ignored.
+// Keys are not seeded by default: seeding changes the entries placement, but Fibonacci hashing is not meant to resist
+// keys crafted to collide.
+func NewInt[TKey Integer, TValue any](config ...HashMapConfig[TKey, TValue]) *IntHashmap[TKey, TValue] {
+	options := Hashmap[TKey, TValue]{
+		loadFactor:      defaultLoadFactor,
+		initialCapacity: defaultInitialCapacity,
+	}
+	for _, configFunc := range config {
+		configFunc(&options)
+	}
+
+	m := &IntHashmap[TKey, TValue]{
+		loadFactor: options.loadFactor,
+		seed:       uint64(options.hashSeed),
+	}
+	slots := 1
+	for slots < int(options.initialCapacity) {
+		slots *= 2
+	}
+	m.allocate(slots)
+	return m
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *IntHashmap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (m *IntHashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	index, found := m.find(key)
+	if found {
+		return m.storage[index].value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+// Insert or update the given value at the given key.
+func (m *IntHashmap[TKey, TValue]) Set(key TKey, value TValue) {
+	if index, found := m.find(key); found {
+		m.storage[index].value = value
+		return
+	}
+
+	if float64(m.length) >= float64(len(m.storage))*float64(m.loadFactor) {
+		m.grow()
+	}
+	m.length++
+	m.insert(key, value)
+}
+
+// Remove the entry with the given key from the hashmap.
+func (m *IntHashmap[TKey, TValue]) Delete(key TKey) {
+	index, found := m.find(key)
+	if !found {
+		return
+	}
+	m.length--
+
+	// Shift next entries one slot back, until an empty slot or an entry at its ideal index
+	mask := len(m.storage) - 1
+	next := (index + 1) & mask
+	for m.storage[next].alive && m.distance(next) != 0 {
+		m.storage[index] = m.storage[next]
+		index = next
+		next = (next + 1) & mask
+	}
+	m.storage[index] = mapEntry[TKey, TValue]{}
+}
+
+// Remove all entries from the hashmap.
+func (m *IntHashmap[TKey, TValue]) Clear() {
+	m.storage = make([]mapEntry[TKey, TValue], len(m.storage))
+	m.length = 0
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *IntHashmap[TKey, TValue]) Len() int {
+	return m.length
+}
+
+// Get all entries stored in the hashmap.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *IntHashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	entries := make([]KeyValue[TKey, TValue], 0, m.length)
+	for _, entry := range m.storage {
+		if entry.alive {
+			entries = append(entries, KeyValue[TKey, TValue]{
+				Key:   entry.key,
+				Value: entry.value,
+			})
+		}
+	}
+	return entries
+}
+
+// Main lookup function, try to find the index of the given key.
+func (m *IntHashmap[TKey, TValue]) find(key TKey) (int, bool) {
+	mask := len(m.storage) - 1
+	index := m.idealIndex(key)
+	for distance := 0; ; distance++ {
+		entry := &m.storage[index]
+		if !entry.alive || m.distance(index) < distance {
+			// Empty slot, or the key would have taken this slot
+			return 0, false
+		}
+		if entry.key == key {
+			return index, true
+		}
+		index = (index + 1) & mask
+	}
+}
+
+// Place an entry whose key is not in the hashmap.
+func (m *IntHashmap[TKey, TValue]) insert(key TKey, value TValue) {
+	mask := len(m.storage) - 1
+	index := m.idealIndex(key)
+	var distance int
+	for {
+		if !m.storage[index].alive {
+			m.storage[index] = mapEntry[TKey, TValue]{key, value, true}
+			return
+		}
+
+		if curSlotDistance := m.distance(index); distance > curSlotDistance {
+			// Insert data in this slot and continue to find a new spot for the previous data
+			m.storage[index].key, key = key, m.storage[index].key
+			m.storage[index].value, value = value, m.storage[index].value
+			distance = curSlotDistance
+		}
+		distance++
+		index = (index + 1) & mask
+	}
+}
+
+// Compute the index at which the given key should be located, using Fibonacci hashing.
+func (m *IntHashmap[TKey, TValue]) idealIndex(key TKey) int {
+	return int(((uint64(key) ^ m.seed) * fibonacciMultiplier) >> m.shift)
+}
+
+// Get the distance between the entry at the given index and the ideal index of its key.
+func (m *IntHashmap[TKey, TValue]) distance(index int) int {
+	return (index - m.idealIndex(m.storage[index].key)) & (len(m.storage) - 1)
+}
+
+// Allocate empty storage with the given number of slots, which must be a power of 2.
+func (m *IntHashmap[TKey, TValue]) allocate(slots int) {
+	m.storage = make([]mapEntry[TKey, TValue], slots)
+	m.shift = uint(64 - bits.TrailingZeros(uint(slots)))
+}
+
+// Allocate a new storage, twice as big as previous storage, and reinsert all entries.
+func (m *IntHashmap[TKey, TValue]) grow() {
+	oldStorage := m.storage
+	m.allocate(len(oldStorage) * 2)
+	for _, entry := range oldStorage {
+		if entry.alive {
+			m.insert(entry.key, entry.value)
+		}
+	}
+}
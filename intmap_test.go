@@ -0,0 +1,54 @@
+package hashmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Apply random operations to integer keyed hashmaps and to a native map, then compare their content.
+func TestIntHashmap(t *testing.T) {
+	for _, seed := range []uintptr{0, 0x5bd1e995} {
+		rng := rand.New(rand.NewSource(1))
+		m := NewInt(WithInitialCapacity[int64, int](8), WithSeed[int64, int](seed))
+		expected := map[int64]int{}
+
+		for i := range 200_000 {
+			key := int64(rng.Intn(20_000) - 10_000)
+			if i%2 == 0 {
+				key <<= 40 // Sparse keys
+			}
+			switch op := rng.Intn(10); {
+			case op < 6:
+				m.Set(key, i)
+				expected[key] = i
+			case op < 9:
+				m.Delete(key)
+				delete(expected, key)
+			default:
+				value, found := m.TryGet(key)
+				expectedValue, expectedFound := expected[key]
+				if found != expectedFound || value != expectedValue {
+					t.Fatalf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+				}
+			}
+		}
+
+		if m.Len() != len(expected) {
+			t.Errorf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+		}
+		entries := m.GetEntries()
+		if len(entries) != len(expected) {
+			t.Errorf("invalid entries length. expected=%d, got=%d", len(expected), len(entries))
+		}
+		for _, kv := range entries {
+			if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+				t.Errorf("invalid entry for key=%d. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+			}
+		}
+
+		m.Clear()
+		if m.Len() != 0 || len(m.GetEntries()) != 0 {
+			t.Errorf("invalid length after clear. expected=0, got=%d", m.Len())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package hashmap
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+)
+
+// Status of a multi-word CAS.
+const (
+	kcasUndecided int32 = iota
+	kcasSucceeded
+	kcasFailed
+)
+
+// Content of a word of a concurrent table: an entry, a shard stamp, or the descriptor of an ongoing multi-word CAS.
+// Contents are immutable, words are changed by swapping their content pointer. Empty slots hold nil.
+type concurrentWord[TKey comparable, TValue any] struct {
+	key      TKey
+	hash     uint64
+	value    TValue
+	deadline int64  // Entries: expiration time in Unix nanoseconds, 0 if the entry doesn't expire
+	stamp    uint64 // Shard stamps: incremented by every write of a slot of the shard
+	frozen   bool   // Shard stamps: the table is being resized, its slots can't be written anymore
+	copied   bool   // Shard stamps: the entries of the shard were copied to the next table
+	rdcss    *rdcssDescriptor[TKey, TValue]
+	kcas     *kcasDescriptor[TKey, TValue]
+}
+
+// Word changed by a multi-word CAS.
+type kcasEntry[TKey comparable, TValue any] struct {
+	word  *atomic.Pointer[concurrentWord[TKey, TValue]]
+	order uint64 // Words are acquired by increasing order, so that operations never help each other in a cycle
+	old   *concurrentWord[TKey, TValue]
+	new   *concurrentWord[TKey, TValue]
+}
+
+// Multi-word CAS in progress, any goroutine finding it in a word helps completing it.
+type kcasDescriptor[TKey comparable, TValue any] struct {
+	status  atomic.Int32
+	entries []kcasEntry[TKey, TValue]
+	marker  *concurrentWord[TKey, TValue] // Installed in the words while the operation is in progress
+}
+
+// Installation of a multi-word CAS marker in one of its words, conditional to the operation being undecided.
+type rdcssDescriptor[TKey comparable, TValue any] struct {
+	kcas   *kcasDescriptor[TKey, TValue]
+	entry  *kcasEntry[TKey, TValue]
+	marker *concurrentWord[TKey, TValue]
+}
+
+// Atomically swap the content of all words of the entries: either all of them held their old content and
+// are changed, or none is. Entries whose old and new contents are the same are only compared.
+//
+// This is the lock-free multi-word CAS of Harris, Fraser and Pratt: the operation marker is installed in every word,
+// in order, with a restricted double-compare single-swap, then the operation status is decided and markers are
+// replaced by the new or old contents. Operations finding a marker complete its operation before proceeding.
+func kcas[TKey comparable, TValue any](entries []kcasEntry[TKey, TValue]) bool {
+	slices.SortFunc(entries, func(a, b kcasEntry[TKey, TValue]) int {
+		return cmp.Compare(a.order, b.order)
+	})
+	d := &kcasDescriptor[TKey, TValue]{entries: entries}
+	d.marker = &concurrentWord[TKey, TValue]{kcas: d}
+	return d.run()
+}
+
+// Complete the operation, returns whether it succeeded.
+func (d *kcasDescriptor[TKey, TValue]) run() bool {
+	if d.status.Load() == kcasUndecided {
+		status := kcasSucceeded
+		for i := 0; i < len(d.entries) && status == kcasSucceeded; {
+			current := d.acquire(&d.entries[i])
+			yieldStep()
+			if current != nil && current.kcas != nil && current.kcas != d {
+				// Held by another operation, which only holds words ordered before the ones it waits for
+				current.kcas.run()
+				continue
+			}
+			if current != d.entries[i].old && current != d.marker {
+				status = kcasFailed
+			}
+			i++
+		}
+		d.status.CompareAndSwap(kcasUndecided, status)
+	}
+
+	succeeded := d.status.Load() == kcasSucceeded
+	for i := range d.entries {
+		entry := &d.entries[i]
+		if succeeded {
+			entry.word.CompareAndSwap(d.marker, entry.new)
+		} else {
+			entry.word.CompareAndSwap(d.marker, entry.old)
+		}
+	}
+	return succeeded
+}
+
+// Install the operation marker in the word of the entry if it holds its old content, while the operation is undecided.
+// Returns the content found in the word, which is the old content if the marker was installed.
+func (d *kcasDescriptor[TKey, TValue]) acquire(entry *kcasEntry[TKey, TValue]) *concurrentWord[TKey, TValue] {
+	r := &rdcssDescriptor[TKey, TValue]{kcas: d, entry: entry}
+	r.marker = &concurrentWord[TKey, TValue]{rdcss: r}
+	for {
+		if entry.word.CompareAndSwap(entry.old, r.marker) {
+			r.complete()
+			return entry.old
+		}
+		current := entry.word.Load()
+		switch {
+		case current != nil && current.rdcss != nil:
+			current.rdcss.complete()
+		case current != entry.old:
+			return current
+		}
+	}
+}
+
+// Replace the installation marker by the operation marker if the operation is still undecided, or restore the old content.
+func (r *rdcssDescriptor[TKey, TValue]) complete() {
+	if r.kcas.status.Load() == kcasUndecided {
+		r.entry.word.CompareAndSwap(r.marker, r.kcas.marker)
+	} else {
+		r.entry.word.CompareAndSwap(r.marker, r.entry.old)
+	}
+}
+
+// Read the logical content of a word, completing the operations in progress on it.
+func readWord[TKey comparable, TValue any](word *atomic.Pointer[concurrentWord[TKey, TValue]]) *concurrentWord[TKey, TValue] {
+	for {
+		current := word.Load()
+		switch {
+		case current == nil:
+			return nil
+		case current.rdcss != nil:
+			current.rdcss.complete()
+		case current.kcas != nil:
+			current.kcas.run()
+		default:
+			return current
+		}
+	}
+}
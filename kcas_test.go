@@ -0,0 +1,79 @@
+package hashmap
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Goroutines transfer amounts between words with multi-word CAS, while others read consistent snapshots:
+// the total must never change.
+func TestKCAS(t *testing.T) {
+	const words = 8
+	const total = words * 100
+	var accounts [words]atomic.Pointer[concurrentWord[int, int]]
+	for i := range accounts {
+		accounts[i].Store(&concurrentWord[int, int]{value: total / words})
+	}
+	compareAll := func() ([]*concurrentWord[int, int], bool) {
+		snapshot := make([]*concurrentWord[int, int], words)
+		entries := make([]kcasEntry[int, int], words)
+		for i := range accounts {
+			snapshot[i] = readWord(&accounts[i])
+			entries[i] = kcasEntry[int, int]{word: &accounts[i], order: uint64(i), old: snapshot[i], new: snapshot[i]}
+		}
+		return snapshot, kcas(entries)
+	}
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(g)))
+			for range 5_000 {
+				if g%4 == 0 {
+					if snapshot, ok := compareAll(); ok {
+						sum := 0
+						for _, word := range snapshot {
+							sum += word.value
+						}
+						if sum != total {
+							t.Errorf("inconsistent snapshot. expected=%d, got=%d", total, sum)
+							return
+						}
+					}
+					continue
+				}
+
+				from, to := rng.Intn(words), rng.Intn(words)
+				if from == to {
+					continue
+				}
+				for {
+					fromWord, toWord := readWord(&accounts[from]), readWord(&accounts[to])
+					if kcas([]kcasEntry[int, int]{
+						{word: &accounts[to], order: uint64(to), old: toWord, new: &concurrentWord[int, int]{value: toWord.value + 1}},
+						{word: &accounts[from], order: uint64(from), old: fromWord, new: &concurrentWord[int, int]{value: fromWord.value - 1}},
+					}) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sum := 0
+	for i := range accounts {
+		word := accounts[i].Load()
+		if word.rdcss != nil || word.kcas != nil {
+			t.Fatalf("operation left unfinished in word %d", i)
+		}
+		sum += word.value
+	}
+	if sum != total {
+		t.Errorf("invalid total. expected=%d, got=%d", total, sum)
+	}
+}
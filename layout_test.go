@@ -0,0 +1,91 @@
+package hashmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var layouts = []struct {
+	name   string
+	layout Layout
+}{
+	{"RobinHood", RobinHood},
+	{"Swiss", Swiss},
+	{"Cuckoo", Cuckoo},
+	{"RobinHoodSoA", RobinHoodSoA},
+}
+
+// Apply random operations to every layout and to a native map, then compare their content.
+func TestLayouts(t *testing.T) {
+	for _, l := range layouts {
+		t.Run(l.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			m := New(WithLayout[int, int](l.layout), WithInitialCapacity[int, int](8))
+			expected := map[int]int{}
+
+			for i := range 200_000 {
+				key := rng.Intn(20_000)
+				switch op := rng.Intn(10); {
+				case op < 6:
+					m.Set(key, i)
+					expected[key] = i
+				case op < 9:
+					m.Delete(key)
+					delete(expected, key)
+				default:
+					value, found := m.TryGet(key)
+					expectedValue, expectedFound := expected[key]
+					if found != expectedFound || value != expectedValue {
+						t.Fatalf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+					}
+				}
+				if i == 150_000 {
+					m.Clear()
+					clear(expected)
+				}
+			}
+
+			if m.Len() != len(expected) {
+				t.Errorf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+			}
+			entries := m.GetEntries()
+			if len(entries) != len(expected) {
+				t.Errorf("invalid entries length. expected=%d, got=%d", len(expected), len(entries))
+			}
+			for _, kv := range entries {
+				if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+					t.Errorf("invalid entry for key=%d. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+				}
+			}
+			for key, expectedValue := range expected {
+				if value := m.Get(key); value != expectedValue {
+					t.Errorf("retrieved invalid value for key=%d. expected=%d, got=%d", key, expectedValue, value)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutEncoding(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[string, int](l.layout))
+		m.Set("key1", 123)
+		m.Set("key2", 456)
+
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("marshaling failed: %v", err)
+		}
+		var decoded Hashmap[string, int]
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unmarshaling failed: %v", err)
+		}
+
+		if decoded.layout != l.layout {
+			t.Errorf("invalid layout. expected=%d, got=%d", l.layout, decoded.layout)
+		}
+		if decoded.Len() != 2 || decoded.Get("key1") != 123 || decoded.Get("key2") != 456 {
+			t.Errorf("invalid decoded entries for layout=%s: %v", l.name, decoded.GetEntries())
+		}
+	}
+}
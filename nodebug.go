@@ -0,0 +1,6 @@
+//go:build !hashmapdebug
+
+package hashmap
+
+// Check that the given precomputed hash matches the key, only enabled by the hashmapdebug build tag.
+func (m *Hashmap[TKey, TValue]) checkHash(key TKey, hash uint64) {}
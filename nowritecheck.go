@@ -0,0 +1,6 @@
+//go:build hashmapnocheck
+
+package hashmap
+
+// Concurrent misuse detection is disabled by the hashmapnocheck build tag.
+const misuseChecks = false
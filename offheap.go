@@ -0,0 +1,59 @@
+package hashmap
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Check whether the storage can be allocated outside of the Go heap: this is only possible for the RobinHood layout,
+// on unix systems, when keys and values don't contain pointers.
+func (m *Hashmap[TKey, TValue]) canUseOffHeap() bool {
+	return offHeapSupported && m.layout == RobinHood && !hasPointers(reflect.TypeFor[mapEntry[TKey, TValue]]())
+}
+
+// Allocate a zeroed entries storage of the given capacity, outside of the Go heap if enabled.
+func (m *Hashmap[TKey, TValue]) allocStorage(capacity int) []mapEntry[TKey, TValue] {
+	if !m.offHeap {
+		return make([]mapEntry[TKey, TValue], capacity)
+	}
+
+	var entry mapEntry[TKey, TValue]
+	data, err := allocOffHeap(capacity * int(unsafe.Sizeof(entry)))
+	if err != nil {
+		panic("hashmap: unable to allocate off-heap storage: " + err.Error())
+	}
+	return unsafe.Slice((*mapEntry[TKey, TValue])(unsafe.Pointer(unsafe.SliceData(data))), capacity)
+}
+
+// Release a storage allocated by allocStorage. It must not be used afterwards.
+func (m *Hashmap[TKey, TValue]) freeStorage(storage []mapEntry[TKey, TValue]) error {
+	if !m.offHeap || len(storage) == 0 {
+		return nil
+	}
+
+	var entry mapEntry[TKey, TValue]
+	data := unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(storage))), len(storage)*int(unsafe.Sizeof(entry)))
+	return freeOffHeap(data)
+}
+
+// Check whether values of the given type can hold pointers, which the garbage collector must be able to see.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		// Pointers, strings, slices, maps, channels, functions and interfaces
+		return true
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package hashmap
+
+import "errors"
+
+// Off-heap storage is only supported on unix systems, the Go heap is used instead.
+const offHeapSupported = false
+
+func allocOffHeap(size int) ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func freeOffHeap(data []byte) error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package hashmap
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOffHeapStorage(t *testing.T) {
+	m := New(WithOffHeapStorage[uint64, [32]byte](), WithInitialCapacity[uint64, [32]byte](8))
+	if m.offHeap != offHeapSupported {
+		t.Fatalf("invalid off-heap flag. expected=%t, got=%t", offHeapSupported, m.offHeap)
+	}
+
+	for i := range uint64(10_000) {
+		m.Set(i, [32]byte{byte(i)})
+	}
+	for i := range uint64(5_000) {
+		m.Delete(i * 2)
+	}
+	for i := range uint64(10_000) {
+		value, found := m.TryGet(i)
+		if found != (i%2 == 1) || (found && value[0] != byte(i)) {
+			t.Errorf("invalid lookup for key=%d. got=(%v, %t)", i, value[0], found)
+		}
+	}
+
+	m.Clear()
+	if m.Len() != 0 || len(m.GetEntries()) != 0 {
+		t.Errorf("invalid length after clear. expected=0, got=%d", m.Len())
+	}
+	m.Set(1, [32]byte{1})
+	if m.Get(1)[0] != 1 {
+		t.Error("invalid value after clear")
+	}
+
+	// Snapshots are off-heap clones, which are released separately
+	snapshot := m.Snapshot()
+	if snapshot.shared || &snapshot.storage[0] == &m.storage[0] {
+		t.Error("off-heap storage must not be shared")
+	}
+	if err := snapshot.Close(); err != nil {
+		t.Fatalf("snapshot close failed: %v", err)
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("second close failed: %v", err)
+	}
+}
+
+func TestOffHeapFallback(t *testing.T) {
+	m := New(WithOffHeapStorage[string, int]())
+	if m.offHeap {
+		t.Error("off-heap storage must not be used for pointerful types")
+	}
+	m.Set("key", 1)
+	if m.Get("key") != 1 {
+		t.Error("invalid value")
+	}
+
+	swiss := New(WithOffHeapStorage[int, int](), WithLayout[int, int](Swiss))
+	if swiss.offHeap {
+		t.Error("off-heap storage must not be used for the Swiss layout")
+	}
+}
+
+func TestHasPointers(t *testing.T) {
+	cases := []struct {
+		value    any
+		expected bool
+	}{
+		{uint64(0), false},
+		{[32]byte{}, false},
+		{struct {
+			a int
+			b [2]float64
+		}{}, false},
+		{[0]*int{}, false},
+		{"", true},
+		{[]byte{}, true},
+		{struct{ p *int }{}, true},
+		{[4]any{}, true},
+	}
+	for _, c := range cases {
+		if got := hasPointers(reflect.TypeOf(c.value)); got != c.expected {
+			t.Errorf("invalid result for %T. expected=%t, got=%t", c.value, c.expected, got)
+		}
+	}
+}
+
+func TestSmallOffHeapStorage(t *testing.T) {
+	m := NewSmall(WithOffHeapStorage[uint64, uint64]())
+	for i := range uint64(100) {
+		m.Set(i, i)
+	}
+	if m.large.offHeap != offHeapSupported {
+		t.Errorf("invalid off-heap flag. expected=%t, got=%t", offHeapSupported, m.large.offHeap)
+	}
+
+	m.Clear()
+	if m.large != nil || m.Len() != 0 {
+		t.Error("entries must be stored inline after clear")
+	}
+	for i := range uint64(100) {
+		m.Set(i, i)
+	}
+	if m.Get(99) != 99 {
+		t.Error("invalid value after clear")
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	if err := NewSmall[uint64, uint64]().Close(); err != nil {
+		t.Fatalf("inline close failed: %v", err)
+	}
+}
+
+func TestReadMostlyOffHeapStorage(t *testing.T) {
+	m := NewReadMostly(WithOffHeapStorage[uint64, uint64]())
+	for i := range uint64(1000) {
+		m.Set(i, i) // Merges the delta into new bases
+	}
+	if base := m.current.Load().base; base.offHeap || base.Len() == 0 {
+		t.Error("read mostly bases must not use off-heap storage")
+	}
+}
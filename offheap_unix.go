@@ -0,0 +1,17 @@
+//go:build unix
+
+package hashmap
+
+import "syscall"
+
+const offHeapSupported = true
+
+// Map anonymous memory, which is zeroed and not managed by the garbage collector.
+func allocOffHeap(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// Release memory mapped by allocOffHeap.
+func freeOffHeap(data []byte) error {
+	return syscall.Munmap(data)
+}
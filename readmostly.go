@@ -0,0 +1,226 @@
+package hashmap
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+const minDeltaMergeLength = 64 // Minimum number of changes kept in the delta overlay before merging it into the base
+
+// Change of the delta overlay of a ReadMostlyHashmap
+type deltaEntry[TValue any] struct {
+	value    TValue
+	deleted  bool
+	sequence uint64 // Sequence of the version which made the change
+}
+
+// Immutable version of a ReadMostlyHashmap, made of a base hashmap and an overlay of the changes made since it was built.
+// Both use the same hash function and seed, so keys are only hashed once.
+type readMostlyVersion[TKey comparable, TValue any] struct {
+	base         *Hashmap[TKey, TValue]
+	delta        *Hashmap[TKey, deltaEntry[TValue]]
+	length       int
+	sequence     uint64 // Number of versions published before this one
+	baseSequence uint64 // Sequence of the version the base was built for, keys of the base changed at or before it
+}
+
+// Concurrent hashmap optimized for workloads with rare writes.
+//
+// Readers atomically load the current immutable version and look it up without any lock. Writers are serialized,
+// and publish a new version for every Update: changes are recorded in a small delta overlay, which is copied by
+// each write, and is merged into a new base hashmap once it grows past the square root of the base length.
+// This bounds the amortized cost of a write to O(sqrt(n)) instead of copying the whole table.
+type ReadMostlyHashmap[TKey comparable, TValue any] struct {
+	current atomic.Pointer[readMostlyVersion[TKey, TValue]]
+	writeMu sync.Mutex
+	config  []HashMapConfig[TKey, TValue] // Used to create base hashmaps
+}
+
+// Batch of changes applied atomically by ReadMostlyHashmap.Update.
+type ReadMostlyBatch[TKey comparable, TValue any] struct {
+	version *readMostlyVersion[TKey, TValue] // Version being built, not published yet
+}
+
+// Instanciate a new read mostly hashmap. The configuration is applied to base hashmaps.
+//
+// WithOffHeapStorage is ignored: replaced bases may still be read, so they are left to the garbage collector.
+func NewReadMostly[TKey comparable, TValue any](config ...HashMapConfig[TKey, TValue]) *ReadMostlyHashmap[TKey, TValue] {
+	config = append(append([]HashMapConfig[TKey, TValue]{}, config...), func(hmap *Hashmap[TKey, TValue]) {
+		hmap.offHeap = false
+	})
+	m := &ReadMostlyHashmap[TKey, TValue]{config: config}
+	m.current.Store(newReadMostlyVersion(New(config...)))
+	return m
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *ReadMostlyHashmap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.current.Load().tryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (m *ReadMostlyHashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	return m.current.Load().tryGet(key)
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *ReadMostlyHashmap[TKey, TValue]) Len() int {
+	return m.current.Load().length
+}
+
+// Get all entries stored in the hashmap, from a single version.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *ReadMostlyHashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	return m.current.Load().entries()
+}
+
+// Insert or update the given value at the given key, and publish the change.
+func (m *ReadMostlyHashmap[TKey, TValue]) Set(key TKey, value TValue) {
+	m.Update(func(batch *ReadMostlyBatch[TKey, TValue]) {
+		batch.Set(key, value)
+	})
+}
+
+// Remove the entry with the given key from the hashmap, and publish the change.
+func (m *ReadMostlyHashmap[TKey, TValue]) Delete(key TKey) {
+	m.Update(func(batch *ReadMostlyBatch[TKey, TValue]) {
+		batch.Delete(key)
+	})
+}
+
+// Remove all entries from the hashmap.
+func (m *ReadMostlyHashmap[TKey, TValue]) Clear() {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	next := newReadMostlyVersion(New(m.config...))
+	next.sequence = m.current.Load().sequence + 1
+	next.baseSequence = next.sequence
+	m.current.Store(next)
+}
+
+// Apply a batch of changes, and publish them all at once: readers either see none or all of them.
+//
+// Writers are serialized, the batch must not be used after fn returned.
+func (m *ReadMostlyHashmap[TKey, TValue]) Update(fn func(batch *ReadMostlyBatch[TKey, TValue])) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	current := m.current.Load()
+	next := &readMostlyVersion[TKey, TValue]{
+		base:         current.base,
+		delta:        newDelta(current.base, current.delta.Len()),
+		length:       current.length,
+		sequence:     current.sequence + 1,
+		baseSequence: current.baseSequence,
+	}
+	for _, change := range current.delta.GetEntries() {
+		next.delta.Set(change.Key, change.Value)
+	}
+
+	fn(&ReadMostlyBatch[TKey, TValue]{version: next})
+
+	if next.delta.Len() > max(minDeltaMergeLength, int(math.Sqrt(float64(next.base.Len())))) {
+		next = m.merge(next)
+	}
+	m.current.Store(next)
+}
+
+// Build a version with a new base holding all entries of the given version, and an empty delta.
+func (m *ReadMostlyHashmap[TKey, TValue]) merge(version *readMostlyVersion[TKey, TValue]) *readMostlyVersion[TKey, TValue] {
+	config := append([]HashMapConfig[TKey, TValue]{}, m.config...)
+	config = append(config, WithInitialCapacity[TKey, TValue](uint(version.base.capacity())))
+	base := New(config...)
+	for _, entry := range version.entries() {
+		base.Set(entry.Key, entry.Value)
+	}
+	merged := newReadMostlyVersion(base)
+	merged.sequence = version.sequence
+	merged.baseSequence = version.sequence
+	return merged
+}
+
+// Get the value associated with the given key, including changes of the batch.
+func (b *ReadMostlyBatch[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	return b.version.tryGet(key)
+}
+
+// Insert or update the given value at the given key.
+func (b *ReadMostlyBatch[TKey, TValue]) Set(key TKey, value TValue) {
+	if _, found := b.version.tryGet(key); !found {
+		b.version.length++
+	}
+	b.version.delta.SetHashed(key, b.version.base.Hash(key), deltaEntry[TValue]{value: value, sequence: b.version.sequence})
+}
+
+// Remove the entry with the given key.
+func (b *ReadMostlyBatch[TKey, TValue]) Delete(key TKey) {
+	if _, found := b.version.tryGet(key); !found {
+		return
+	}
+	b.version.length--
+	b.version.delta.SetHashed(key, b.version.base.Hash(key), deltaEntry[TValue]{deleted: true, sequence: b.version.sequence})
+}
+
+// Create a version with the given base and an empty delta.
+func newReadMostlyVersion[TKey comparable, TValue any](base *Hashmap[TKey, TValue]) *readMostlyVersion[TKey, TValue] {
+	return &readMostlyVersion[TKey, TValue]{
+		base:   base,
+		delta:  newDelta(base, 0),
+		length: base.Len(),
+	}
+}
+
+// Look up the key in the delta, then in the base.
+func (v *readMostlyVersion[TKey, TValue]) tryGet(key TKey) (TValue, bool) {
+	hash := v.base.Hash(key)
+	if change, found := v.delta.TryGetHashed(key, hash); found {
+		if change.deleted {
+			var zeroEntry TValue
+			return zeroEntry, false
+		}
+		return change.value, true
+	}
+	return v.base.TryGetHashed(key, hash)
+}
+
+// Check whether the key may have been changed by a version published after the given sequence.
+// Keys of the base are all considered changed by the version the base was built for.
+func (v *readMostlyVersion[TKey, TValue]) changedAfter(key TKey, sequence uint64) bool {
+	if change, found := v.delta.TryGetHashed(key, v.base.Hash(key)); found {
+		return change.sequence > sequence
+	}
+	return v.baseSequence > sequence
+}
+
+// Get all entries of the version, with changes of the delta applied to the base.
+func (v *readMostlyVersion[TKey, TValue]) entries() []KeyValue[TKey, TValue] {
+	entries := make([]KeyValue[TKey, TValue], 0, v.length)
+	for _, entry := range v.base.GetEntries() {
+		if _, changed := v.delta.TryGet(entry.Key); !changed {
+			entries = append(entries, entry)
+		}
+	}
+	for _, change := range v.delta.GetEntries() {
+		if !change.Value.deleted {
+			entries = append(entries, KeyValue[TKey, TValue]{Key: change.Key, Value: change.Value.value})
+		}
+	}
+	return entries
+}
+
+// Create a delta overlay sharing the hash function and seed of the hashmap, with room for the given number of changes.
+// This is not a Hashmap method, which would be an instantiation cycle.
+func newDelta[TKey comparable, TValue any](m *Hashmap[TKey, TValue], length int) *Hashmap[TKey, deltaEntry[TValue]] {
+	capacity := uint(defaultInitialCapacity)
+	for float64(length) >= float64(capacity)*float64(defaultLoadFactor) {
+		capacity *= 2
+	}
+	return New(
+		WithHashFunc[TKey, deltaEntry[TValue]](m.hashFunc),
+		WithSeed[TKey, deltaEntry[TValue]](m.hashSeed),
+		WithInitialCapacity[TKey, deltaEntry[TValue]](capacity),
+	)
+}
@@ -0,0 +1,99 @@
+package hashmap
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// Apply random batches of changes to a read mostly hashmap and to a native map, then compare their content.
+func TestReadMostly(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := NewReadMostly[int, int]()
+	expected := map[int]int{}
+
+	for i := range 20_000 {
+		m.Update(func(batch *ReadMostlyBatch[int, int]) {
+			for j := range rng.Intn(10) {
+				key := rng.Intn(5_000)
+				if rng.Intn(3) == 0 {
+					batch.Delete(key)
+					delete(expected, key)
+				} else {
+					batch.Set(key, i*10+j)
+					expected[key] = i*10 + j
+				}
+				value, found := batch.TryGet(key)
+				expectedValue, expectedFound := expected[key]
+				if found != expectedFound || value != expectedValue {
+					t.Fatalf("invalid batch lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+				}
+			}
+		})
+
+		key := rng.Intn(5_000)
+		value, found := m.TryGet(key)
+		expectedValue, expectedFound := expected[key]
+		if found != expectedFound || value != expectedValue {
+			t.Fatalf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+		}
+		if m.Len() != len(expected) {
+			t.Fatalf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+		}
+	}
+
+	entries := m.GetEntries()
+	if len(entries) != len(expected) {
+		t.Errorf("invalid entries length. expected=%d, got=%d", len(expected), len(entries))
+	}
+	for _, kv := range entries {
+		if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+			t.Errorf("invalid entry for key=%d. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+		}
+	}
+
+	m.Clear()
+	if m.Len() != 0 || len(m.GetEntries()) != 0 {
+		t.Errorf("invalid length after clear. expected=0, got=%d", m.Len())
+	}
+}
+
+// Readers must see either all changes of a batch, or none of them.
+func TestReadMostlyAtomicBatches(t *testing.T) {
+	m := NewReadMostly[string, int]()
+	m.Update(func(batch *ReadMostlyBatch[string, int]) {
+		batch.Set("a", 0)
+		batch.Set("b", 0)
+	})
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				entries := m.GetEntries()
+				if len(entries) != 2 || entries[0].Value != entries[1].Value {
+					t.Errorf("partial batch observed: %v", entries)
+					return
+				}
+				_ = m.Get("a")
+			}
+		}()
+	}
+
+	for i := range 2_000 {
+		m.Update(func(batch *ReadMostlyBatch[string, int]) {
+			batch.Set("a", i)
+			batch.Set("b", i)
+		})
+	}
+	close(done)
+	wg.Wait()
+}
@@ -0,0 +1,132 @@
+package hashmap
+
+const smallMapCapacity = 8 // Number of entries stored inline by a SmallHashmap
+
+// Hashmap optimized for a handful of entries.
+//
+// Up to 8 entries are stored in an inline array and looked up with a linear scan, without hashing keys
+// or allocating. When the array overflows, entries are moved to a Hashmap, which is used from then on.
+// The zero value is an empty map ready to use.
+type SmallHashmap[TKey comparable, TValue any] struct {
+	entries [smallMapCapacity]KeyValue[TKey, TValue]
+	length  int                           // Number of inline entries
+	large   *Hashmap[TKey, TValue]        // Set once the inline array overflowed
+	config  []HashMapConfig[TKey, TValue] // Used to create the large hashmap
+}
+
+// Instanciate a new small hashmap. The configuration is applied to the Hashmap created when the inline array overflows.
+func NewSmall[TKey comparable, TValue any](config ...HashMapConfig[TKey, TValue]) *SmallHashmap[TKey, TValue] {
+	return &SmallHashmap[TKey, TValue]{config: config}
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *SmallHashmap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (m *SmallHashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	if m.large != nil {
+		return m.large.TryGet(key)
+	}
+	if index := m.find(key); index >= 0 {
+		return m.entries[index].Value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+// Insert or update the given value at the given key.
+func (m *SmallHashmap[TKey, TValue]) Set(key TKey, value TValue) {
+	if m.large != nil {
+		m.large.Set(key, value)
+		return
+	}
+	if index := m.find(key); index >= 0 {
+		m.entries[index].Value = value
+		return
+	}
+
+	if m.length == smallMapCapacity {
+		// Move inline entries to a Robin Hood hashmap
+		m.large = New(append([]HashMapConfig[TKey, TValue]{WithInitialCapacity[TKey, TValue](4 * smallMapCapacity)}, m.config...)...)
+		for _, entry := range m.entries {
+			m.large.Set(entry.Key, entry.Value)
+		}
+		m.large.Set(key, value)
+		m.entries = [smallMapCapacity]KeyValue[TKey, TValue]{}
+		m.length = 0
+		return
+	}
+	m.entries[m.length] = KeyValue[TKey, TValue]{Key: key, Value: value}
+	m.length++
+}
+
+// Remove the entry with the given key from the hashmap.
+func (m *SmallHashmap[TKey, TValue]) Delete(key TKey) {
+	if m.large != nil {
+		m.large.Delete(key)
+		return
+	}
+	index := m.find(key)
+	if index < 0 {
+		return
+	}
+
+	// Move the last entry in the freed slot
+	m.length--
+	m.entries[index] = m.entries[m.length]
+	m.entries[m.length] = KeyValue[TKey, TValue]{}
+}
+
+// Remove all entries from the hashmap, entries are stored inline again.
+func (m *SmallHashmap[TKey, TValue]) Clear() {
+	m.entries = [smallMapCapacity]KeyValue[TKey, TValue]{}
+	m.length = 0
+	if m.large != nil {
+		m.large.Close() // Releases its off-heap storage, if any
+		m.large = nil
+	}
+}
+
+// Release the storage of the hashmap created when the inline array overflowed, this is only required when off-heap
+// storage is used, see WithOffHeapStorage.
+//
+// The hashmap must not be used after being closed.
+func (m *SmallHashmap[TKey, TValue]) Close() error {
+	if m.large == nil {
+		return nil
+	}
+	return m.large.Close()
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *SmallHashmap[TKey, TValue]) Len() int {
+	if m.large != nil {
+		return m.large.Len()
+	}
+	return m.length
+}
+
+// Get all entries stored in the hashmap.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *SmallHashmap[TKey, TValue]) GetEntries() []KeyValue[TKey, TValue] {
+	if m.large != nil {
+		return m.large.GetEntries()
+	}
+	entries := make([]KeyValue[TKey, TValue], m.length)
+	copy(entries, m.entries[:m.length])
+	return entries
+}
+
+// Get the index of the inline entry with the given key, or -1.
+func (m *SmallHashmap[TKey, TValue]) find(key TKey) int {
+	for i := range m.length {
+		if m.entries[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
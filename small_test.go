@@ -0,0 +1,76 @@
+package hashmap
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Apply random operations to a small hashmap and to a native map, then compare their content.
+// Few keys are used, so that the map often stays inline.
+func TestSmallHashmap(t *testing.T) {
+	for _, keysCount := range []int{smallMapCapacity, 100} {
+		rng := rand.New(rand.NewSource(1))
+		var m SmallHashmap[int, int]
+		expected := map[int]int{}
+
+		for i := range 50_000 {
+			key := rng.Intn(keysCount)
+			switch op := rng.Intn(10); {
+			case op < 6:
+				m.Set(key, i)
+				expected[key] = i
+			case op < 9:
+				m.Delete(key)
+				delete(expected, key)
+			default:
+				value, found := m.TryGet(key)
+				expectedValue, expectedFound := expected[key]
+				if found != expectedFound || value != expectedValue {
+					t.Fatalf("invalid lookup for key=%d. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+				}
+			}
+			if m.Len() != len(expected) {
+				t.Fatalf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+			}
+		}
+
+		if overflowed := m.large != nil; overflowed != (keysCount > smallMapCapacity) {
+			t.Errorf("invalid overflow state for %d keys: %t", keysCount, overflowed)
+		}
+		for _, kv := range m.GetEntries() {
+			if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+				t.Errorf("invalid entry for key=%d. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+			}
+		}
+
+		m.Clear()
+		if m.Len() != 0 || m.large != nil {
+			t.Errorf("invalid state after clear. length=%d, overflowed=%t", m.Len(), m.large != nil)
+		}
+	}
+}
+
+func TestSmallHashmapAllocations(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		var m SmallHashmap[int, int]
+		for i := range smallMapCapacity {
+			m.Set(i, i)
+		}
+		for i := range smallMapCapacity {
+			_ = m.Get(i)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("invalid allocations count. expected=0, got=%v", allocs)
+	}
+}
+
+func TestNewSmallConfig(t *testing.T) {
+	m := NewSmall(WithLayout[int, int](Swiss))
+	for i := range smallMapCapacity + 1 {
+		m.Set(i, i)
+	}
+	if m.large == nil || m.large.layout != Swiss {
+		t.Error("configuration not applied to the overflow hashmap")
+	}
+}
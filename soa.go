@@ -0,0 +1,163 @@
+package hashmap
+
+import "math"
+
+// Robin Hood storage engine with a structure of arrays layout.
+//
+// Slot metadata, keys and values are stored in separate arrays, so probing only touches the dense metadata
+// and the keys: values are only read once the key is found. The metadata of a slot is its probe distance + 1,
+// or 0 for an empty slot. Since distances are stored, they don't have to be computed by hashing keys again.
+type soaTable[TKey comparable, TValue any] struct {
+	meta       []uint8
+	keys       []TKey
+	values     []TValue
+	mask       int
+	length     int
+	loadFactor float32
+	hash       func(TKey) uint64
+}
+
+// Create a structure of arrays table with room for at least the given number of slots.
+func newSoATable[TKey comparable, TValue any](capacity int, loadFactor float32, hash func(TKey) uint64) *soaTable[TKey, TValue] {
+	slots := 1
+	for slots < capacity {
+		slots *= 2
+	}
+	t := &soaTable[TKey, TValue]{
+		loadFactor: loadFactor,
+		hash:       hash,
+	}
+	t.allocate(slots)
+	return t
+}
+
+func (t *soaTable[TKey, TValue]) get(key TKey, hash uint64) (TValue, bool) {
+	index, found := t.find(key, hash)
+	if found {
+		return t.values[index], true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+func (t *soaTable[TKey, TValue]) set(key TKey, hash uint64, value TValue) bool {
+	if index, found := t.find(key, hash); found {
+		t.values[index] = value
+		return false
+	}
+
+	if float64(t.length) >= float64(len(t.meta))*float64(t.loadFactor) {
+		t.grow()
+	}
+	t.length++
+	t.insert(key, hash, value)
+	return true
+}
+
+func (t *soaTable[TKey, TValue]) delete(key TKey, hash uint64) bool {
+	index, found := t.find(key, hash)
+	if !found {
+		return false
+	}
+	t.length--
+
+	// Shift next entries one slot back, until an empty slot or an entry at its ideal index
+	next := (index + 1) & t.mask
+	for t.meta[next] > 1 {
+		t.meta[index] = t.meta[next] - 1
+		t.keys[index] = t.keys[next]
+		t.values[index] = t.values[next]
+		index = next
+		next = (next + 1) & t.mask
+	}
+
+	var zeroKey TKey
+	var zeroValue TValue
+	t.meta[index] = 0
+	t.keys[index] = zeroKey
+	t.values[index] = zeroValue
+	return true
+}
+
+func (t *soaTable[TKey, TValue]) clear() {
+	t.allocate(len(t.meta))
+}
+
+func (t *soaTable[TKey, TValue]) capacity() int {
+	return len(t.meta)
+}
+
+func (t *soaTable[TKey, TValue]) forEach(fn func(key TKey, value TValue)) {
+	for index, meta := range t.meta {
+		if meta != 0 {
+			fn(t.keys[index], t.values[index])
+		}
+	}
+}
+
+// Main lookup function, try to find the index of the given key.
+func (t *soaTable[TKey, TValue]) find(key TKey, hash uint64) (int, bool) {
+	index := int(hash) & t.mask
+	for distance := uint8(1); ; distance++ {
+		meta := t.meta[index]
+		if meta < distance {
+			// Empty slot, or the key would have taken this slot
+			return 0, false
+		}
+		if meta == distance && t.keys[index] == key {
+			return index, true
+		}
+		index = (index + 1) & t.mask
+	}
+}
+
+// Place an entry whose key is not in the table.
+func (t *soaTable[TKey, TValue]) insert(key TKey, hash uint64, value TValue) {
+	index := int(hash) & t.mask
+	distance := 1
+	for {
+		if t.meta[index] == 0 {
+			t.meta[index] = uint8(distance)
+			t.keys[index] = key
+			t.values[index] = value
+			return
+		}
+
+		if int(t.meta[index]) < distance {
+			// Insert entry in this slot and continue to find a new spot for the previous one
+			meta := t.meta[index]
+			t.meta[index] = uint8(distance)
+			t.keys[index], key = key, t.keys[index]
+			t.values[index], value = value, t.values[index]
+			distance = int(meta)
+		}
+
+		distance++
+		if distance > math.MaxUint8 {
+			// Distances must fit in the metadata, spread entries over a larger table
+			t.grow()
+			t.insert(key, t.hash(key), value)
+			return
+		}
+		index = (index + 1) & t.mask
+	}
+}
+
+// Allocate empty storage with the given number of slots.
+func (t *soaTable[TKey, TValue]) allocate(slots int) {
+	t.meta = make([]uint8, slots)
+	t.keys = make([]TKey, slots)
+	t.values = make([]TValue, slots)
+	t.mask = slots - 1
+}
+
+// Allocate a new storage, twice as big as previous storage, and reinsert all entries.
+func (t *soaTable[TKey, TValue]) grow() {
+	oldMeta, oldKeys, oldValues := t.meta, t.keys, t.values
+	t.allocate(len(oldMeta) * 2)
+	for index, meta := range oldMeta {
+		if meta != 0 {
+			t.insert(oldKeys[index], t.hash(oldKeys[index]), oldValues[index])
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package store
+
+const defaultMaxSegmentSize int64 = 64 << 20
+
+// Configuration function to customize a Store.
+type StoreConfig func(*Store)
+
+// Specify the size beyond which the active segment is sealed and a new one is started.
+//
+// This must be positive, or the default size (64 MiB) will be applied.
+func WithMaxSegmentSize(size int64) StoreConfig {
+	if size <= 0 {
+		size = defaultMaxSegmentSize
+	}
+
+	return func(s *Store) {
+		s.maxSegmentSize = size
+	}
+}
+
+// Flush every write to stable storage before returning.
+//
+// Without it, records are written to the OS page cache only: they survive a process crash but not a system crash.
+func WithSyncWrites() StoreConfig {
+	return func(s *Store) {
+		s.syncWrites = true
+	}
+}
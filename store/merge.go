@@ -0,0 +1,211 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"slices"
+
+	"github.com/valsov/hashmap"
+)
+
+// Hint entry layout:
+//
+//	seq (uint64) | record offset (uint64) | record size (uint32) | key length (uint32) | key
+//
+// A hint file lists the live records of a merged segment, followed by the crc32 of the whole list.
+const hintHeaderSize = 24
+
+// Segment being written by a merge
+type mergeOutput struct {
+	id    uint32
+	file  *os.File
+	buf   *bufio.Writer
+	size  int64
+	hints []byte
+}
+
+// Rewrite all segments, keeping only the latest record of each live key, to reclaim disk space.
+//
+// Merged segments come with a hint file, so they don't have to be scanned when the store is opened.
+// Writes are blocked during the merge.
+func (s *Store) Merge() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	if err := s.segments[s.activeID].Sync(); err != nil {
+		return err
+	}
+	oldIDs := make([]uint32, 0, len(s.segments))
+	for id := range s.segments {
+		oldIDs = append(oldIDs, id)
+	}
+	slices.Sort(oldIDs)
+
+	outputs, locations, err := s.writeMergedSegments(s.activeID + 1)
+	if err != nil {
+		for _, output := range outputs {
+			output.file.Close()
+			os.Remove(output.file.Name())
+			os.Remove(s.path(output.id, hintExt))
+		}
+		return err
+	}
+
+	// Merged segments are durable, switch over to them
+	nextID := s.activeID + 1
+	for _, output := range outputs {
+		s.segments[output.id] = output.file
+		nextID = output.id + 1
+	}
+	for _, kv := range locations.GetEntries() {
+		s.keydir.Set(kv.Key, kv.Value)
+	}
+
+	if err := s.openActive(nextID); err != nil {
+		return err
+	}
+
+	// Oldest segments are removed first, so a crash can't leave a deleted record without its tombstone
+	for _, id := range oldIDs {
+		s.segments[id].Close()
+		delete(s.segments, id)
+		if err := os.Remove(s.path(id, segmentExt)); err != nil {
+			return err
+		}
+		if err := os.Remove(s.path(id, hintExt)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy the records referenced by the keydir to new segments, starting at the given id.
+func (s *Store) writeMergedSegments(firstID uint32) ([]*mergeOutput, *hashmap.Hashmap[string, recordLocation], error) {
+	var outputs []*mergeOutput
+	var output *mergeOutput
+	locations := hashmap.New[string, recordLocation]()
+
+	for _, kv := range s.keydir.GetEntries() {
+		if output == nil || output.size >= s.maxSegmentSize {
+			if output != nil {
+				if err := s.finishOutput(output); err != nil {
+					return outputs, nil, err
+				}
+			}
+			file, err := os.OpenFile(s.path(firstID+uint32(len(outputs)), segmentExt), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+			if err != nil {
+				return outputs, nil, err
+			}
+			output = &mergeOutput{
+				id:   firstID + uint32(len(outputs)),
+				file: file,
+				buf:  bufio.NewWriter(file),
+			}
+			outputs = append(outputs, output)
+		}
+
+		header, _, value, err := s.readRecord(kv.Value)
+		if err != nil {
+			return outputs, nil, err
+		}
+		record := encodeRecord(header.seq, kv.Key, value, false)
+		if _, err := output.buf.Write(record); err != nil {
+			return outputs, nil, err
+		}
+
+		location := recordLocation{
+			segment: output.id,
+			offset:  output.size,
+			size:    uint32(len(record)),
+			seq:     header.seq,
+		}
+		locations.Set(kv.Key, location)
+		output.hints = appendHint(output.hints, kv.Key, location)
+		output.size += int64(len(record))
+	}
+
+	if output != nil {
+		if err := s.finishOutput(output); err != nil {
+			return outputs, nil, err
+		}
+	}
+	return outputs, locations, nil
+}
+
+// Flush a merged segment to stable storage and write its hint file.
+func (s *Store) finishOutput(output *mergeOutput) error {
+	if err := output.buf.Flush(); err != nil {
+		return err
+	}
+	if err := output.file.Sync(); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so a hint file is either complete or absent
+	hints := binary.LittleEndian.AppendUint32(output.hints, crc32.ChecksumIEEE(output.hints))
+	tmpPath := s.path(output.id, hintExt+tmpExt)
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(hints); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path(output.id, hintExt))
+}
+
+// Add the records listed in the hint file of a segment to the keydir.
+//
+// false is returned if the segment has no valid hint file, in which case it must be scanned.
+func (s *Store) loadHints(id uint32, deleted *hashmap.Hashmap[string, uint64]) bool {
+	data, err := os.ReadFile(s.path(id, hintExt))
+	if err != nil || len(data) < 4 {
+		return false
+	}
+	hints := data[:len(data)-4]
+	if crc32.ChecksumIEEE(hints) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return false
+	}
+
+	for len(hints) > 0 {
+		if len(hints) < hintHeaderSize {
+			return false
+		}
+		location := recordLocation{
+			segment: id,
+			seq:     binary.LittleEndian.Uint64(hints),
+			offset:  int64(binary.LittleEndian.Uint64(hints[8:])),
+			size:    binary.LittleEndian.Uint32(hints[16:]),
+		}
+		keySize := int(binary.LittleEndian.Uint32(hints[20:]))
+		if len(hints) < hintHeaderSize+keySize {
+			return false
+		}
+		key := string(hints[hintHeaderSize : hintHeaderSize+keySize])
+		s.applyRecord(key, location, false, deleted)
+		hints = hints[hintHeaderSize+keySize:]
+	}
+	return true
+}
+
+// Append a hint entry for the given record.
+func appendHint(hints []byte, key string, location recordLocation) []byte {
+	hints = binary.LittleEndian.AppendUint64(hints, location.seq)
+	hints = binary.LittleEndian.AppendUint64(hints, uint64(location.offset))
+	hints = binary.LittleEndian.AppendUint32(hints, location.size)
+	hints = binary.LittleEndian.AppendUint32(hints, uint32(len(key)))
+	return append(hints, key...)
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// Record layout:
+//
+//	crc32 (uint32) | seq (uint64) | key length (uint32) | value length (uint32) | key | value
+//
+// The checksum covers everything after itself. A deletion is recorded as a tombstone, a record
+// with a value length of tombstoneLength and no value. All integers are little-endian.
+const (
+	recordHeaderSize = 20
+	tombstoneLength  = math.MaxUint32
+)
+
+var errTruncatedRecord = errors.New("store: truncated or corrupted record")
+
+// Decoded record header
+type recordHeader struct {
+	crc       uint32
+	seq       uint64
+	keySize   uint32
+	valueSize uint32
+}
+
+// Whether the record marks a deletion.
+func (h recordHeader) isTombstone() bool {
+	return h.valueSize == tombstoneLength
+}
+
+// Total size of the record, header included.
+func (h recordHeader) recordSize() int64 {
+	size := recordHeaderSize + int64(h.keySize)
+	if !h.isTombstone() {
+		size += int64(h.valueSize)
+	}
+	return size
+}
+
+// Encode a record, value is ignored for tombstones.
+func encodeRecord(seq uint64, key string, value []byte, tombstone bool) []byte {
+	valueSize := uint32(len(value))
+	if tombstone {
+		value = nil
+		valueSize = tombstoneLength
+	}
+
+	record := make([]byte, recordHeaderSize, recordHeaderSize+len(key)+len(value))
+	binary.LittleEndian.PutUint64(record[4:], seq)
+	binary.LittleEndian.PutUint32(record[12:], uint32(len(key)))
+	binary.LittleEndian.PutUint32(record[16:], valueSize)
+	record = append(record, key...)
+	record = append(record, value...)
+	binary.LittleEndian.PutUint32(record, crc32.ChecksumIEEE(record[4:]))
+	return record
+}
+
+// Decode a record header.
+func decodeHeader(data []byte) recordHeader {
+	return recordHeader{
+		crc:       binary.LittleEndian.Uint32(data),
+		seq:       binary.LittleEndian.Uint64(data[4:]),
+		keySize:   binary.LittleEndian.Uint32(data[12:]),
+		valueSize: binary.LittleEndian.Uint32(data[16:]),
+	}
+}
+
+// Decode and verify a complete record, returning its key and value.
+func decodeRecord(record []byte) (recordHeader, string, []byte, error) {
+	if len(record) < recordHeaderSize {
+		return recordHeader{}, "", nil, errTruncatedRecord
+	}
+	header := decodeHeader(record)
+	if int64(len(record)) != header.recordSize() || crc32.ChecksumIEEE(record[4:]) != header.crc {
+		return recordHeader{}, "", nil, errTruncatedRecord
+	}
+
+	key := string(record[recordHeaderSize : recordHeaderSize+header.keySize])
+	var value []byte
+	if !header.isTombstone() {
+		value = record[recordHeaderSize+header.keySize:]
+	}
+	return header, key, value, nil
+}
+
+// Read the records of a segment of the given size in order, calling fn with each record and its offset.
+//
+// The offset following the last valid record is returned, along with errTruncatedRecord if the scan
+// stopped on an incomplete or corrupted record.
+func scanRecords(r io.Reader, size int64, fn func(offset int64, header recordHeader, key string)) (int64, error) {
+	reader := bufio.NewReader(r)
+	var offset int64
+	headerBuf := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, headerBuf); err != nil {
+			if err == io.EOF {
+				return offset, nil
+			}
+			return offset, errTruncatedRecord
+		}
+
+		header := decodeHeader(headerBuf)
+		if header.recordSize() > size-offset {
+			return offset, errTruncatedRecord
+		}
+		record := make([]byte, header.recordSize())
+		copy(record, headerBuf)
+		if _, err := io.ReadFull(reader, record[recordHeaderSize:]); err != nil {
+			return offset, errTruncatedRecord
+		}
+		header, key, _, err := decodeRecord(record)
+		if err != nil {
+			return offset, err
+		}
+
+		fn(offset, header, key)
+		offset += int64(len(record))
+	}
+}
@@ -0,0 +1,349 @@
+// Package store implements a Bitcask-style persistent key-value store.
+//
+// Records are appended to segment files in a directory, and an in-memory Hashmap (the keydir) maps each key
+// to the location of its latest record. Reads take a single disk access, and old segments are rewritten by Merge
+// to reclaim the space of overwritten and deleted records.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/valsov/hashmap"
+)
+
+const (
+	segmentExt = ".seg"
+	hintExt    = ".hint"
+	tmpExt     = ".tmp"
+)
+
+var (
+	ErrNotFound  = errors.New("store: key not found")
+	ErrCorrupted = errors.New("store: corrupted record")
+	ErrClosed    = errors.New("store: closed")
+)
+
+// Location of a record in the segment files
+type recordLocation struct {
+	segment uint32
+	offset  int64
+	size    uint32
+	seq     uint64 // Sequence number of the record, the highest one wins when rebuilding the keydir
+}
+
+// Persistent key-value store, safe for concurrent use.
+type Store struct {
+	mu             sync.RWMutex
+	dir            string
+	keydir         *hashmap.Hashmap[string, recordLocation]
+	segments       map[uint32]*os.File
+	activeID       uint32
+	activeSize     int64
+	seq            uint64 // Last used sequence number
+	maxSegmentSize int64
+	syncWrites     bool
+	closed         bool
+}
+
+// Open the store located in the given directory, creating it if needed.
+//
+// The keydir is rebuilt from the hint files of merged segments, and by scanning the other segments.
+// An incomplete record left at the end of the last segment by a crash is discarded, while a bad record in a sealed
+// segment makes Open fail with ErrCorrupted.
+func Open(dir string, config ...StoreConfig) (*Store, error) {
+	s := &Store{
+		dir:            dir,
+		keydir:         hashmap.New[string, recordLocation](),
+		segments:       map[uint32]*os.File{},
+		maxSegmentSize: defaultMaxSegmentSize,
+	}
+	for _, configFunc := range config {
+		configFunc(s)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	ids, err := s.listSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := hashmap.New[string, uint64]() // Sequence number of the latest deletion of each key
+	var lastHasHint bool
+	for i, id := range ids {
+		lastHasHint, err = s.loadSegment(id, i == len(ids)-1, deleted)
+		if err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+	}
+
+	if len(ids) > 0 && !lastHasHint {
+		// Keep appending to the last segment, unless it is a merged one: its hint file would become incomplete
+		s.activeID = ids[len(ids)-1]
+		info, err := s.segments[s.activeID].Stat()
+		if err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+		s.activeSize = info.Size()
+	} else {
+		nextID := uint32(1)
+		if len(ids) > 0 {
+			nextID = ids[len(ids)-1] + 1
+		}
+		if err := s.openActive(nextID); err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Get the value associated with the given key, or ErrNotFound.
+func (s *Store) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+
+	location, found := s.keydir.TryGet(key)
+	if !found {
+		return nil, ErrNotFound
+	}
+	_, _, value, err := s.readRecord(location)
+	return value, err
+}
+
+// Insert or update the value at the given key.
+func (s *Store) Put(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	location, err := s.append(key, value, false)
+	if err != nil {
+		return err
+	}
+	s.keydir.Set(key, location)
+	return nil
+}
+
+// Remove the entry with the given key from the store.
+func (s *Store) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	if _, found := s.keydir.TryGet(key); !found {
+		return nil
+	}
+	if _, err := s.append(key, nil, true); err != nil {
+		return err
+	}
+	s.keydir.Delete(key)
+	return nil
+}
+
+// Get the number of entries stored in the store.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keydir.Len()
+}
+
+// Flush the active segment and close all segment files. The store can't be used afterwards.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+
+	err := s.segments[s.activeID].Sync()
+	if closeErr := s.closeFiles(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Append a record to the active segment, sealing it first if it became too large.
+func (s *Store) append(key string, value []byte, tombstone bool) (recordLocation, error) {
+	if s.activeSize >= s.maxSegmentSize {
+		if err := s.rotate(); err != nil {
+			return recordLocation{}, err
+		}
+	}
+
+	record := encodeRecord(s.seq+1, key, value, tombstone)
+	active := s.segments[s.activeID]
+	if _, err := active.WriteAt(record, s.activeSize); err != nil {
+		// The next record will overwrite the partial write
+		return recordLocation{}, err
+	}
+	if s.syncWrites {
+		if err := active.Sync(); err != nil {
+			return recordLocation{}, err
+		}
+	}
+
+	s.seq++
+	location := recordLocation{
+		segment: s.activeID,
+		offset:  s.activeSize,
+		size:    uint32(len(record)),
+		seq:     s.seq,
+	}
+	s.activeSize += int64(len(record))
+	return location, nil
+}
+
+// Seal the active segment and start a new one.
+func (s *Store) rotate() error {
+	if err := s.segments[s.activeID].Sync(); err != nil {
+		return err
+	}
+	return s.openActive(s.activeID + 1)
+}
+
+// Create the segment with the given id and make it the active one.
+func (s *Store) openActive(id uint32) error {
+	file, err := os.OpenFile(s.path(id, segmentExt), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	s.segments[id] = file
+	s.activeID = id
+	s.activeSize = 0
+	return nil
+}
+
+// Read and verify the record at the given location.
+func (s *Store) readRecord(location recordLocation) (recordHeader, string, []byte, error) {
+	record := make([]byte, location.size)
+	if _, err := s.segments[location.segment].ReadAt(record, location.offset); err != nil {
+		return recordHeader{}, "", nil, err
+	}
+	header, key, value, err := decodeRecord(record)
+	if err != nil {
+		return recordHeader{}, "", nil, ErrCorrupted
+	}
+	return header, key, value, nil
+}
+
+// Open a segment and add its records to the keydir, from its hint file if it has one.
+//
+// Only the last segment can end with an incomplete record left by a crash, which is discarded.
+// Sealed segments were synced before the next one was created, a bad record in them is a corruption.
+func (s *Store) loadSegment(id uint32, last bool, deleted *hashmap.Hashmap[string, uint64]) (bool, error) {
+	file, err := os.OpenFile(s.path(id, segmentExt), os.O_RDWR, 0o644)
+	if err != nil {
+		return false, err
+	}
+	s.segments[id] = file
+
+	if s.loadHints(id, deleted) {
+		return true, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	end, err := scanRecords(file, info.Size(), func(offset int64, header recordHeader, key string) {
+		location := recordLocation{
+			segment: id,
+			offset:  offset,
+			size:    uint32(header.recordSize()),
+			seq:     header.seq,
+		}
+		s.applyRecord(key, location, header.isTombstone(), deleted)
+	})
+	if err != nil {
+		if !last {
+			return false, fmt.Errorf("%w: segment %d at offset %d", ErrCorrupted, id, end)
+		}
+		// Discard the incomplete record a crash left behind
+		if err := file.Truncate(end); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Update the keydir with a record found while loading segments, unless a more recent record was already found.
+func (s *Store) applyRecord(key string, location recordLocation, tombstone bool, deleted *hashmap.Hashmap[string, uint64]) {
+	s.seq = max(s.seq, location.seq)
+	if existing, found := s.keydir.TryGet(key); found && existing.seq > location.seq {
+		return
+	}
+	if deletedSeq, found := deleted.TryGet(key); found && deletedSeq > location.seq {
+		return
+	}
+
+	if tombstone {
+		s.keydir.Delete(key)
+		deleted.Set(key, location.seq)
+	} else {
+		s.keydir.Set(key, location)
+	}
+}
+
+// List the ids of the segments in the store directory, in ascending order. Leftover temporary files are removed.
+func (s *Store) listSegments() ([]uint32, error) {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint32
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if strings.HasSuffix(name, tmpExt) {
+			if err := os.Remove(filepath.Join(s.dir, name)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 32)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint32(id))
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// Close all segment files.
+func (s *Store) closeFiles() error {
+	var err error
+	for _, file := range s.segments {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// Get the path of a store file.
+func (s *Store) path(id uint32, ext string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%010d%s", id, ext))
+}
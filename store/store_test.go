@@ -0,0 +1,273 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestPutGetDelete(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer s.Close()
+
+	mustPut(t, s, "key1", "value1")
+	mustPut(t, s, "key2", "value2")
+	mustPut(t, s, "key1", "updated")
+	if err := s.Delete("key2"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := s.Delete("notfound"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	expectValue(t, s, "key1", "updated")
+	expectNotFound(t, s, "key2")
+	expectNotFound(t, s, "notfound")
+	if s.Len() != 1 {
+		t.Errorf("invalid length. expected=1, got=%d", s.Len())
+	}
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, WithMaxSegmentSize(256))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for i := range 100 {
+		mustPut(t, s, "key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	for i := range 50 {
+		mustPut(t, s, "key"+strconv.Itoa(i), "updated"+strconv.Itoa(i))
+	}
+	for i := 90; i < 100; i++ {
+		if err := s.Delete("key" + strconv.Itoa(i)); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	s, err = Open(dir, WithMaxSegmentSize(256))
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer s.Close()
+
+	expectEntries(t, s)
+	mustPut(t, s, "new", "value")
+	expectValue(t, s, "new", "value")
+}
+
+func TestTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	mustPut(t, s, "key1", "value1")
+	mustPut(t, s, "key2", "value2")
+	activePath := s.path(s.activeID, segmentExt)
+	if err := s.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	// Simulate a crash in the middle of a write
+	file, err := os.OpenFile(activePath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("segment open failed: %v", err)
+	}
+	file.Write(encodeRecord(100, "key3", []byte("value3"), false)[:25])
+	file.Close()
+
+	s, err = Open(dir)
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer s.Close()
+
+	expectValue(t, s, "key1", "value1")
+	expectValue(t, s, "key2", "value2")
+	expectNotFound(t, s, "key3")
+
+	// The torn record must have been discarded
+	mustPut(t, s, "key3", "value3")
+	expectValue(t, s, "key3", "value3")
+}
+
+func TestCorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer s.Close()
+	mustPut(t, s, "key1", "value1")
+
+	file, err := os.OpenFile(s.path(s.activeID, segmentExt), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("segment open failed: %v", err)
+	}
+	file.WriteAt([]byte("X"), int64(recordHeaderSize+len("key1")))
+	file.Close()
+
+	if _, err := s.Get("key1"); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("invalid error. expected=%v, got=%v", ErrCorrupted, err)
+	}
+}
+
+// A bad record in a sealed segment must be reported, not truncated with the records following it.
+func TestCorruptedSealedSegment(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, WithMaxSegmentSize(64))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for i := range 10 {
+		mustPut(t, s, "key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	sealedPath := s.path(1, segmentExt)
+	if s.activeID == 1 {
+		t.Fatal("the first segment must be sealed")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	file, err := os.OpenFile(sealedPath, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("segment open failed: %v", err)
+	}
+	file.WriteAt([]byte("X"), int64(recordHeaderSize+len("key0")))
+	file.Close()
+	info, err := os.Stat(sealedPath)
+	if err != nil {
+		t.Fatalf("segment stat failed: %v", err)
+	}
+
+	if _, err := Open(dir, WithMaxSegmentSize(64)); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("invalid error. expected=%v, got=%v", ErrCorrupted, err)
+	}
+	if after, err := os.Stat(sealedPath); err != nil || after.Size() != info.Size() {
+		t.Errorf("sealed segment was truncated. expected=%d, got=%d", info.Size(), after.Size())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, WithMaxSegmentSize(256))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	for i := range 100 {
+		mustPut(t, s, "key"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	for i := range 50 {
+		mustPut(t, s, "key"+strconv.Itoa(i), "updated"+strconv.Itoa(i))
+	}
+	for i := 90; i < 100; i++ {
+		if err := s.Delete("key" + strconv.Itoa(i)); err != nil {
+			t.Fatalf("delete failed: %v", err)
+		}
+	}
+
+	sizeBefore := segmentsSize(t, dir)
+	if err := s.Merge(); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if sizeAfter := segmentsSize(t, dir); sizeAfter >= sizeBefore {
+		t.Errorf("merge didn't reclaim space. before=%d, after=%d", sizeBefore, sizeAfter)
+	}
+	expectEntries(t, s)
+
+	hints, _ := filepath.Glob(filepath.Join(dir, "*"+hintExt))
+	if len(hints) == 0 {
+		t.Errorf("no hint file written")
+	}
+
+	// Writes after the merge must survive a reopen along with merged data
+	mustPut(t, s, "key0", "after merge")
+	if err := s.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	s, err = Open(dir, WithMaxSegmentSize(256))
+	if err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer s.Close()
+
+	expectValue(t, s, "key0", "after merge")
+	expectValue(t, s, "key1", "updated1")
+	expectValue(t, s, "key60", "value60")
+	expectNotFound(t, s, "key95")
+}
+
+// Check the entries written by TestReopen and TestMerge.
+func expectEntries(t *testing.T, s *Store) {
+	t.Helper()
+	for i := range 100 {
+		key := "key" + strconv.Itoa(i)
+		switch {
+		case i < 50:
+			expectValue(t, s, key, "updated"+strconv.Itoa(i))
+		case i < 90:
+			expectValue(t, s, key, "value"+strconv.Itoa(i))
+		default:
+			expectNotFound(t, s, key)
+		}
+	}
+	if s.Len() != 90 {
+		t.Errorf("invalid length. expected=90, got=%d", s.Len())
+	}
+}
+
+func mustPut(t *testing.T, s *Store, key, value string) {
+	t.Helper()
+	if err := s.Put(key, []byte(value)); err != nil {
+		t.Fatalf("put failed for key=%s: %v", key, err)
+	}
+}
+
+func expectValue(t *testing.T, s *Store, key, expected string) {
+	t.Helper()
+	value, err := s.Get(key)
+	if err != nil {
+		t.Errorf("get failed for key=%s: %v", key, err)
+		return
+	}
+	if string(value) != expected {
+		t.Errorf("retrieved invalid value for key=%s. expected=%s, got=%s", key, expected, value)
+	}
+}
+
+func expectNotFound(t *testing.T, s *Store, key string) {
+	t.Helper()
+	if _, err := s.Get(key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("invalid error for key=%s. expected=%v, got=%v", key, ErrNotFound, err)
+	}
+}
+
+// Get the total size of the segment files.
+func segmentsSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatalf("segments listing failed: %v", err)
+	}
+	var size int64
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("file stat failed: %v", err)
+		}
+		size += info.Size()
+	}
+	return size
+}
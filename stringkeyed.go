@@ -0,0 +1,247 @@
+package hashmap
+
+import (
+	"unsafe"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+const arenaChunkSize = 1 << 20 // Size of the arena chunks holding keys, larger keys get their own chunk
+
+// Slot of a StringKeyedHashmap, the key is stored in the arena.
+type stringSlot[TValue any] struct {
+	hash   uint64
+	chunk  uint32 // Index of the arena chunk holding the key
+	offset uint32
+	length uint32
+	alive  bool
+	value  TValue
+}
+
+// Hashmap specialized for string keys.
+//
+// Keys are copied into large byte arenas, and slots reference them by offset and length instead of holding strings:
+// the garbage collector then has a handful of chunks to scan instead of one string per entry.
+// Deleted keys stay in the arena until Compact is called, Garbage tells how many bytes they hold.
+type StringKeyedHashmap[TValue any] struct {
+	storage    []stringSlot[TValue]
+	chunks     [][]byte
+	garbage    int // Bytes of deleted keys still held by the arena
+	length     int
+	loadFactor float32
+	hashFunc   func(uintptr, uintptr) uintptr
+	hashSeed   uintptr
+}
+
+// Instanciate a new string keyed hashmap.
+//
+// The load percentage, initial capacity and hash function configurations are supported, other ones are ignored.
+func NewStringKeyed[TValue any](config ...HashMapConfig[string, TValue]) *StringKeyedHashmap[TValue] {
+	options := Hashmap[string, TValue]{
+		loadFactor:      defaultLoadFactor,
+		initialCapacity: defaultInitialCapacity,
+		hashSeed:        hasher.GenerateSeed(),
+	}
+	for _, configFunc := range config {
+		configFunc(&options)
+	}
+	if options.hashFunc == nil {
+		options.hashFunc = hasher.GetHashFunc[string]()
+	}
+
+	return &StringKeyedHashmap[TValue]{
+		storage:    make([]stringSlot[TValue], options.initialCapacity),
+		loadFactor: options.loadFactor,
+		hashFunc:   options.hashFunc,
+		hashSeed:   options.hashSeed,
+	}
+}
+
+// Get the value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *StringKeyedHashmap[TValue]) Get(key string) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key.
+func (m *StringKeyedHashmap[TValue]) TryGet(key string) (TValue, bool) {
+	index, found := m.find(key, m.hash(key))
+	if found {
+		return m.storage[index].value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+// Insert or update the given value at the given key.
+func (m *StringKeyedHashmap[TValue]) Set(key string, value TValue) {
+	hash := m.hash(key)
+	if index, found := m.find(key, hash); found {
+		m.storage[index].value = value
+		return
+	}
+
+	if float64(m.length) >= float64(len(m.storage))*float64(m.loadFactor) {
+		m.grow()
+	}
+	m.length++
+
+	chunk, offset, dst := m.allocKey(len(key))
+	copy(dst, key)
+	m.insert(stringSlot[TValue]{
+		hash:   hash,
+		chunk:  chunk,
+		offset: offset,
+		length: uint32(len(key)),
+		alive:  true,
+		value:  value,
+	})
+}
+
+// Remove the entry with the given key from the hashmap.
+//
+// The key bytes are only released from the arena by Compact.
+func (m *StringKeyedHashmap[TValue]) Delete(key string) {
+	index, found := m.find(key, m.hash(key))
+	if !found {
+		return
+	}
+	m.length--
+	m.garbage += int(m.storage[index].length)
+
+	// Shift next entries one slot back, until an empty slot or an entry at its ideal index
+	mask := len(m.storage) - 1
+	next := (index + 1) & mask
+	for m.storage[next].alive && m.distance(next) != 0 {
+		m.storage[index] = m.storage[next]
+		index = next
+		next = (next + 1) & mask
+	}
+	m.storage[index] = stringSlot[TValue]{}
+}
+
+// Remove all entries from the hashmap, and release the arena.
+func (m *StringKeyedHashmap[TValue]) Clear() {
+	m.storage = make([]stringSlot[TValue], len(m.storage))
+	m.chunks = nil
+	m.garbage = 0
+	m.length = 0
+}
+
+// Get the number of entries stored in the hashmap.
+func (m *StringKeyedHashmap[TValue]) Len() int {
+	return m.length
+}
+
+// Get all entries stored in the hashmap. Keys are copied out of the arena.
+//
+// The slice ordering is not guaranteed to be the insertion order.
+func (m *StringKeyedHashmap[TValue]) GetEntries() []KeyValue[string, TValue] {
+	entries := make([]KeyValue[string, TValue], 0, m.length)
+	for i := range m.storage {
+		if m.storage[i].alive {
+			entries = append(entries, KeyValue[string, TValue]{
+				Key:   string(m.keyBytes(&m.storage[i])),
+				Value: m.storage[i].value,
+			})
+		}
+	}
+	return entries
+}
+
+// Get the number of bytes of deleted keys still held by the arena, which Compact would release.
+func (m *StringKeyedHashmap[TValue]) Garbage() int {
+	return m.garbage
+}
+
+// Copy the keys of all entries into a new arena, releasing the bytes of deleted keys.
+func (m *StringKeyedHashmap[TValue]) Compact() {
+	oldChunks := m.chunks
+	m.chunks = nil
+	for i := range m.storage {
+		slot := &m.storage[i]
+		if slot.alive {
+			chunk, offset, dst := m.allocKey(int(slot.length))
+			copy(dst, oldChunks[slot.chunk][slot.offset:slot.offset+slot.length])
+			slot.chunk, slot.offset = chunk, offset
+		}
+	}
+	m.garbage = 0
+}
+
+// Main lookup function, try to find the index of the given key.
+func (m *StringKeyedHashmap[TValue]) find(key string, hash uint64) (int, bool) {
+	mask := len(m.storage) - 1
+	index := int(hash) & mask
+	for distance := 0; ; distance++ {
+		slot := &m.storage[index]
+		if !slot.alive || m.distance(index) < distance {
+			// Empty slot, or the key would have taken this slot
+			return 0, false
+		}
+		if slot.hash == hash && string(m.keyBytes(slot)) == key {
+			return index, true
+		}
+		index = (index + 1) & mask
+	}
+}
+
+// Place a slot whose key is not in the hashmap.
+func (m *StringKeyedHashmap[TValue]) insert(slot stringSlot[TValue]) {
+	mask := len(m.storage) - 1
+	index := int(slot.hash) & mask
+	var distance int
+	for {
+		if !m.storage[index].alive {
+			m.storage[index] = slot
+			return
+		}
+
+		if curSlotDistance := m.distance(index); distance > curSlotDistance {
+			// Insert slot here and continue to find a new spot for the previous one
+			m.storage[index], slot = slot, m.storage[index]
+			distance = curSlotDistance
+		}
+		distance++
+		index = (index + 1) & mask
+	}
+}
+
+// Get the distance between the slot at the given index and the ideal index of its key.
+func (m *StringKeyedHashmap[TValue]) distance(index int) int {
+	mask := len(m.storage) - 1
+	return (index - int(m.storage[index].hash)) & mask
+}
+
+// Compute the hash of the given key.
+func (m *StringKeyedHashmap[TValue]) hash(key string) uint64 {
+	return uint64(m.hashFunc(uintptr(unsafe.Pointer(&key)), m.hashSeed))
+}
+
+// Get the bytes of the key of the given slot.
+func (m *StringKeyedHashmap[TValue]) keyBytes(slot *stringSlot[TValue]) []byte {
+	return m.chunks[slot.chunk][slot.offset : slot.offset+slot.length]
+}
+
+// Reserve room for a key of the given length in the arena. Returns its location and the bytes to fill.
+func (m *StringKeyedHashmap[TValue]) allocKey(length int) (uint32, uint32, []byte) {
+	last := len(m.chunks) - 1
+	if last < 0 || len(m.chunks[last])+length > cap(m.chunks[last]) {
+		m.chunks = append(m.chunks, make([]byte, 0, max(arenaChunkSize, length)))
+		last++
+	}
+	offset := len(m.chunks[last])
+	m.chunks[last] = m.chunks[last][:offset+length]
+	return uint32(last), uint32(offset), m.chunks[last][offset:]
+}
+
+// Allocate a new storage, twice as big as previous storage, and reinsert all slots. Keys are not hashed again.
+func (m *StringKeyedHashmap[TValue]) grow() {
+	oldStorage := m.storage
+	m.storage = make([]stringSlot[TValue], len(oldStorage)*2)
+	for _, slot := range oldStorage {
+		if slot.alive {
+			m.insert(slot)
+		}
+	}
+}
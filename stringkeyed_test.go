@@ -0,0 +1,89 @@
+package hashmap
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Apply random operations to a string keyed hashmap and to a native map, then compare their content.
+func TestStringKeyed(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	m := NewStringKeyed(WithInitialCapacity[string, int](8))
+	expected := map[string]int{}
+	largeKeys := make([]string, 10)
+	for i := range largeKeys {
+		largeKeys[i] = strings.Repeat(strconv.Itoa(i), arenaChunkSize+1)
+	}
+
+	for i := range 200_000 {
+		key := strconv.Itoa(rng.Intn(20_000))
+		if rng.Intn(1000) == 0 {
+			key = largeKeys[rng.Intn(len(largeKeys))] // Larger than a chunk
+		}
+		switch op := rng.Intn(10); {
+		case op < 6:
+			m.Set(key, i)
+			expected[key] = i
+		case op < 9:
+			m.Delete(key)
+			delete(expected, key)
+		default:
+			value, found := m.TryGet(key)
+			expectedValue, expectedFound := expected[key]
+			if found != expectedFound || value != expectedValue {
+				t.Fatalf("invalid lookup for key=%.20s. expected=(%d, %t), got=(%d, %t)", key, expectedValue, expectedFound, value, found)
+			}
+		}
+		if i%50_000 == 0 {
+			if i > 0 && m.Garbage() == 0 {
+				t.Fatal("deleted keys not counted as garbage")
+			}
+			m.Compact()
+			if m.Garbage() != 0 {
+				t.Fatalf("invalid garbage after compaction. expected=0, got=%d", m.Garbage())
+			}
+		}
+	}
+
+	if m.Len() != len(expected) {
+		t.Errorf("invalid length. expected=%d, got=%d", len(expected), m.Len())
+	}
+	entries := m.GetEntries()
+	if len(entries) != len(expected) {
+		t.Errorf("invalid entries length. expected=%d, got=%d", len(expected), len(entries))
+	}
+	for _, kv := range entries {
+		if expectedValue, found := expected[kv.Key]; !found || kv.Value != expectedValue {
+			t.Errorf("invalid entry for key=%.20s. expected=(%d, %t), got=%d", kv.Key, expectedValue, found, kv.Value)
+		}
+	}
+	for key, expectedValue := range expected {
+		if value := m.Get(key); value != expectedValue {
+			t.Errorf("retrieved invalid value for key=%.20s. expected=%d, got=%d", key, expectedValue, value)
+		}
+	}
+
+	m.Clear()
+	if m.Len() != 0 || len(m.chunks) != 0 {
+		t.Errorf("invalid state after clear. length=%d, chunks=%d", m.Len(), len(m.chunks))
+	}
+}
+
+func TestStringKeyedEmptyKey(t *testing.T) {
+	m := NewStringKeyed[int]()
+	m.Set("", 1)
+	if value, found := m.TryGet(""); !found || value != 1 {
+		t.Errorf("invalid lookup for empty key. expected=(1, true), got=(%d, %t)", value, found)
+	}
+	m.Delete("")
+	if _, found := m.TryGet(""); found {
+		t.Error("empty key not deleted")
+	}
+	m.Set("key", 1)
+	m.Delete("key")
+	if m.Garbage() != len("key") {
+		t.Errorf("invalid garbage. expected=%d, got=%d", len("key"), m.Garbage())
+	}
+}
@@ -0,0 +1,211 @@
+package hashmap
+
+import "math/bits"
+
+// Swiss table constants.
+//
+// A control byte is either ctrlEmpty, ctrlDeleted or the 7 low bits of the hash of the slot's key (high bit unset).
+// The 8 control bytes of a group are stored in a single word, so a group is matched at once with SWAR operations.
+const (
+	swissGroupSize = 8
+
+	ctrlEmpty   uint8 = 0b1000_0000
+	ctrlDeleted uint8 = 0b1111_1110
+
+	groupLSBs       uint64 = 0x0101010101010101
+	groupMSBs       uint64 = 0x8080808080808080
+	emptyGroupCtrls        = groupLSBs * uint64(ctrlEmpty)
+)
+
+// Swiss table slot
+type swissSlot[TKey, TValue any] struct {
+	key   TKey
+	value TValue
+}
+
+// Swiss table storage engine.
+//
+// Groups are probed with a triangular sequence, which visits every group since their count is a power of 2.
+// A lookup stops at the first group that has an empty slot. The maximum load is 7/8.
+type swissTable[TKey comparable, TValue any] struct {
+	ctrls      []uint64 // One word of control bytes per group
+	slots      []swissSlot[TKey, TValue]
+	groupMask  uint64
+	length     int
+	growthLeft int // Number of empty slots that can be filled before growing, deleted slots are not reused for free
+	hash       func(TKey) uint64
+}
+
+// Create a swiss table with room for at least the given number of slots.
+func newSwissTable[TKey comparable, TValue any](capacity int, hash func(TKey) uint64) *swissTable[TKey, TValue] {
+	groups := 1
+	for groups*swissGroupSize < capacity {
+		groups *= 2
+	}
+	t := &swissTable[TKey, TValue]{hash: hash}
+	t.allocate(groups)
+	return t
+}
+
+func (t *swissTable[TKey, TValue]) get(key TKey, hash uint64) (TValue, bool) {
+	index, found := t.find(key, hash)
+	if found {
+		return t.slots[index].value, true
+	}
+	var zeroEntry TValue
+	return zeroEntry, false
+}
+
+func (t *swissTable[TKey, TValue]) set(key TKey, hash uint64, value TValue) bool {
+	for {
+		h2 := uint8(hash & 0x7f)
+		group := (hash >> 7) & t.groupMask
+		insertIndex := -1
+		for step := uint64(1); ; step++ {
+			ctrls := t.ctrls[group]
+			for matches := matchH2(ctrls, h2); matches != 0; matches &= matches - 1 {
+				index := int(group)*swissGroupSize + bits.TrailingZeros64(matches)/8
+				if t.slots[index].key == key {
+					t.slots[index].value = value
+					return false
+				}
+			}
+
+			// The first free slot of the probe sequence is used, unless the key is found further
+			if free := ctrls & groupMSBs; insertIndex < 0 && free != 0 {
+				insertIndex = int(group)*swissGroupSize + bits.TrailingZeros64(free)/8
+			}
+			if matchEmpty(ctrls) != 0 {
+				break
+			}
+			group = (group + step) & t.groupMask
+		}
+
+		if t.ctrl(insertIndex) == ctrlEmpty {
+			if t.growthLeft == 0 {
+				t.grow()
+				continue
+			}
+			t.growthLeft--
+		}
+		t.setCtrl(insertIndex, h2)
+		t.slots[insertIndex] = swissSlot[TKey, TValue]{key, value}
+		t.length++
+		return true
+	}
+}
+
+func (t *swissTable[TKey, TValue]) delete(key TKey, hash uint64) bool {
+	index, found := t.find(key, hash)
+	if !found {
+		return false
+	}
+
+	// If the group has an empty slot, lookups stop at this group anyway: the slot can be emptied.
+	// Otherwise, lookups must continue past it, so it is marked as deleted.
+	if matchEmpty(t.ctrls[index/swissGroupSize]) != 0 {
+		t.setCtrl(index, ctrlEmpty)
+		t.growthLeft++
+	} else {
+		t.setCtrl(index, ctrlDeleted)
+	}
+	t.slots[index] = swissSlot[TKey, TValue]{}
+	t.length--
+	return true
+}
+
+func (t *swissTable[TKey, TValue]) clear() {
+	t.allocate(len(t.ctrls))
+}
+
+func (t *swissTable[TKey, TValue]) capacity() int {
+	return len(t.slots)
+}
+
+func (t *swissTable[TKey, TValue]) forEach(fn func(key TKey, value TValue)) {
+	for index := range t.slots {
+		if t.ctrl(index)&ctrlEmpty == 0 {
+			fn(t.slots[index].key, t.slots[index].value)
+		}
+	}
+}
+
+// Main lookup function, try to find the index of the given key.
+func (t *swissTable[TKey, TValue]) find(key TKey, hash uint64) (int, bool) {
+	h2 := uint8(hash & 0x7f)
+	group := (hash >> 7) & t.groupMask
+	for step := uint64(1); ; step++ {
+		ctrls := t.ctrls[group]
+		for matches := matchH2(ctrls, h2); matches != 0; matches &= matches - 1 {
+			index := int(group)*swissGroupSize + bits.TrailingZeros64(matches)/8
+			if t.slots[index].key == key {
+				return index, true
+			}
+		}
+		if matchEmpty(ctrls) != 0 {
+			return 0, false
+		}
+		group = (group + step) & t.groupMask
+	}
+}
+
+// Allocate empty storage with the given number of groups.
+func (t *swissTable[TKey, TValue]) allocate(groups int) {
+	t.ctrls = make([]uint64, groups)
+	for i := range t.ctrls {
+		t.ctrls[i] = emptyGroupCtrls
+	}
+	t.slots = make([]swissSlot[TKey, TValue], groups*swissGroupSize)
+	t.groupMask = uint64(groups - 1)
+	t.length = 0
+	t.growthLeft = groups * swissGroupSize * 7 / 8
+}
+
+// Rehash all entries into a new storage, twice as big as the previous one.
+// The size is kept if deleted slots take most of the room, they are reclaimed by the rehash.
+func (t *swissTable[TKey, TValue]) grow() {
+	oldSlots := t.slots
+	oldCtrls := t.ctrls
+	groups := len(t.ctrls)
+	if t.length >= len(t.slots)*7/16 {
+		groups *= 2
+	}
+	t.allocate(groups)
+
+	for index := range oldSlots {
+		if ctrlAt(oldCtrls, index)&ctrlEmpty == 0 {
+			t.set(oldSlots[index].key, t.hash(oldSlots[index].key), oldSlots[index].value)
+		}
+	}
+}
+
+// Get the control byte of a slot.
+func (t *swissTable[TKey, TValue]) ctrl(index int) uint8 {
+	return ctrlAt(t.ctrls, index)
+}
+
+// Set the control byte of a slot.
+func (t *swissTable[TKey, TValue]) setCtrl(index int, ctrl uint8) {
+	shift := (index % swissGroupSize) * 8
+	group := &t.ctrls[index/swissGroupSize]
+	*group = *group&^(0xff<<shift) | uint64(ctrl)<<shift
+}
+
+// Get the control byte of a slot from a control words slice.
+func ctrlAt(ctrls []uint64, index int) uint8 {
+	return uint8(ctrls[index/swissGroupSize] >> ((index % swissGroupSize) * 8))
+}
+
+// Get a mask with the high bit set in every byte of the group that matches the given hash fragment.
+//
+// False positives may occur next to a true match, keys are always compared anyway.
+func matchH2(ctrls uint64, h2 uint8) uint64 {
+	x := ctrls ^ (groupLSBs * uint64(h2))
+	return (x - groupLSBs) &^ x & groupMSBs
+}
+
+// Get a mask with the high bit set in every empty byte of the group.
+func matchEmpty(ctrls uint64) uint64 {
+	// Empty is the only control byte with its high bit set and its second lowest bit unset
+	return ctrls &^ (ctrls << 6) & groupMSBs
+}
@@ -0,0 +1,396 @@
+package hashmap
+
+import "errors"
+
+// Returned by the Commit of a transaction which read a key changed meanwhile by another writer.
+var ErrTxConflict = errors.New("hashmap: transaction conflicts with a concurrent write")
+
+// Transaction on a Hashmap, see Hashmap.Begin.
+type Tx[TKey comparable, TValue any] struct {
+	m       *Hashmap[TKey, TValue]
+	journal []undoEntry[TKey, TValue]
+	done    bool
+}
+
+// Previous state of a key changed by a transaction.
+type undoEntry[TKey, TValue any] struct {
+	key     TKey
+	hash    uint64
+	value   TValue
+	existed bool
+}
+
+// Point of a transaction which can be rolled back to, see Tx.Savepoint.
+type Savepoint int
+
+// Start a transaction. Its changes are applied to the hashmap right away, and recorded in an undo journal
+// so that they can be reverted by Rollback.
+//
+// The hashmap must not be written outside of the transaction until it's committed or rolled back. Hooks registered with
+// OnChange are called for each change of the transaction, and for the changes reverting them.
+func (m *Hashmap[TKey, TValue]) Begin() *Tx[TKey, TValue] {
+	return &Tx[TKey, TValue]{m: m}
+}
+
+// Get the value associated with the given key, including changes of the transaction.
+func (tx *Tx[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := tx.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key, including changes of the transaction.
+func (tx *Tx[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	tx.checkActive()
+	return tx.m.TryGet(key)
+}
+
+// Insert or update the given value at the given key.
+func (tx *Tx[TKey, TValue]) Set(key TKey, value TValue) {
+	tx.checkActive()
+	hash := tx.m.Hash(key)
+	old, existed := tx.m.TryGetHashed(key, hash)
+	tx.journal = append(tx.journal, undoEntry[TKey, TValue]{key: key, hash: hash, value: old, existed: existed})
+	tx.m.SetHashed(key, hash, value)
+}
+
+// Remove the entry with the given key.
+func (tx *Tx[TKey, TValue]) Delete(key TKey) {
+	tx.checkActive()
+	hash := tx.m.Hash(key)
+	old, existed := tx.m.TryGetHashed(key, hash)
+	if !existed {
+		return
+	}
+	tx.journal = append(tx.journal, undoEntry[TKey, TValue]{key: key, hash: hash, value: old, existed: true})
+	tx.m.DeleteHashed(key, hash)
+}
+
+// Get the current point of the transaction, later changes can be reverted with RollbackTo.
+func (tx *Tx[TKey, TValue]) Savepoint() Savepoint {
+	tx.checkActive()
+	return Savepoint(len(tx.journal))
+}
+
+// Revert the changes made since the given savepoint, the transaction stays active.
+//
+// Savepoints taken after the given one are invalidated. It panics if the savepoint is invalid.
+func (tx *Tx[TKey, TValue]) RollbackTo(savepoint Savepoint) {
+	tx.checkActive()
+	if savepoint < 0 || int(savepoint) > len(tx.journal) {
+		panic("hashmap: invalid transaction savepoint")
+	}
+	for i := len(tx.journal) - 1; i >= int(savepoint); i-- {
+		undo := tx.journal[i]
+		if undo.existed {
+			tx.m.SetHashed(undo.key, undo.hash, undo.value)
+		} else {
+			tx.m.DeleteHashed(undo.key, undo.hash)
+		}
+	}
+	clear(tx.journal[savepoint:])
+	tx.journal = tx.journal[:savepoint]
+}
+
+// Keep the changes of the transaction, which must not be used anymore.
+func (tx *Tx[TKey, TValue]) Commit() {
+	tx.checkActive()
+	tx.done = true
+	tx.journal = nil
+}
+
+// Revert all changes of the transaction, which must not be used anymore.
+//
+// Rolling back a committed or rolled back transaction has no effect, so that Rollback can be deferred.
+func (tx *Tx[TKey, TValue]) Rollback() {
+	if tx.done {
+		return
+	}
+	tx.RollbackTo(0)
+	tx.done = true
+	tx.journal = nil
+}
+
+// Panic if the transaction was committed or rolled back.
+func (tx *Tx[TKey, TValue]) checkActive() {
+	if tx.done {
+		panic("hashmap: transaction already committed or rolled back")
+	}
+}
+
+// Transaction on a ReadMostlyHashmap, see ReadMostlyHashmap.Begin.
+type ReadMostlyTx[TKey comparable, TValue any] struct {
+	m        *ReadMostlyHashmap[TKey, TValue]
+	snapshot *readMostlyVersion[TKey, TValue] // Version read by the transaction
+	writes   *Tx[TKey, deltaEntry[TValue]]    // Transaction on the write set, for savepoints
+	reads    *Hashmap[TKey, struct{}]         // Keys read from the snapshot
+}
+
+// Start a transaction. Its changes are kept in a write set, and published all at once by Commit: readers either see
+// none or all of them. Reads of the transaction see its own changes, and the version published when it started for
+// other keys.
+//
+// Transactions don't lock the hashmap. Commit fails with ErrTxConflict if a key read by the transaction was changed
+// meanwhile, so that no update is lost. Keys may be reported as changed when the hashmap merged its versions meanwhile.
+func (m *ReadMostlyHashmap[TKey, TValue]) Begin() *ReadMostlyTx[TKey, TValue] {
+	return &ReadMostlyTx[TKey, TValue]{
+		m:        m,
+		snapshot: m.current.Load(),
+		writes:   New[TKey, deltaEntry[TValue]]().Begin(),
+		reads:    New[TKey, struct{}](),
+	}
+}
+
+// Get the value associated with the given key, including changes of the transaction.
+func (tx *ReadMostlyTx[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := tx.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key, including changes of the transaction.
+func (tx *ReadMostlyTx[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	if change, found := tx.writes.TryGet(key); found {
+		if change.deleted {
+			var zeroEntry TValue
+			return zeroEntry, false
+		}
+		return change.value, true
+	}
+	tx.reads.Set(key, struct{}{})
+	return tx.snapshot.tryGet(key)
+}
+
+// Insert or update the given value at the given key.
+func (tx *ReadMostlyTx[TKey, TValue]) Set(key TKey, value TValue) {
+	tx.writes.Set(key, deltaEntry[TValue]{value: value})
+}
+
+// Remove the entry with the given key.
+func (tx *ReadMostlyTx[TKey, TValue]) Delete(key TKey) {
+	tx.writes.Set(key, deltaEntry[TValue]{deleted: true})
+}
+
+// Get the current point of the transaction, later changes can be reverted with RollbackTo.
+func (tx *ReadMostlyTx[TKey, TValue]) Savepoint() Savepoint {
+	return tx.writes.Savepoint()
+}
+
+// Revert the changes made since the given savepoint, the transaction stays active. See Tx.RollbackTo.
+func (tx *ReadMostlyTx[TKey, TValue]) RollbackTo(savepoint Savepoint) {
+	tx.writes.RollbackTo(savepoint)
+}
+
+// Publish all changes of the transaction at once, the transaction must not be used anymore.
+//
+// Returns ErrTxConflict without publishing anything if a key read by the transaction was changed since it started.
+func (tx *ReadMostlyTx[TKey, TValue]) Commit() error {
+	tx.writes.Commit()
+	changes := tx.writes.m.GetEntries()
+	reads := tx.reads.GetEntries()
+	var err error
+	tx.m.Update(func(batch *ReadMostlyBatch[TKey, TValue]) {
+		for _, read := range reads {
+			if batch.version.changedAfter(read.Key, tx.snapshot.sequence) {
+				err = ErrTxConflict
+				return
+			}
+		}
+		for _, change := range changes {
+			if change.Value.deleted {
+				batch.Delete(change.Key)
+			} else {
+				batch.Set(change.Key, change.Value.value)
+			}
+		}
+	})
+	return err
+}
+
+// Discard all changes of the transaction, which must not be used anymore.
+//
+// Rolling back a committed or rolled back transaction has no effect, so that Rollback can be deferred.
+func (tx *ReadMostlyTx[TKey, TValue]) Rollback() {
+	tx.writes.Rollback()
+}
+
+// Transaction on a ConcurrentHashmap, see ConcurrentHashmap.Begin.
+type ConcurrentTx[TKey comparable, TValue any] struct {
+	m      *ConcurrentHashmap[TKey, TValue]
+	writes *Tx[TKey, deltaEntry[TValue]]                 // Transaction on the write set, for savepoints
+	reads  *Hashmap[TKey, *concurrentWord[TKey, TValue]] // Entries read by the transaction, nil for missing keys
+}
+
+// Start a transaction. Its changes are kept in a write set, and applied all at once by Commit with a single multi-word
+// CAS: readers either see none or all of them. Reads of the transaction see its own changes, and keys read again by
+// the transaction keep the value first read.
+//
+// Transactions don't lock the hashmap. Commit fails with ErrTxConflict if a key read by the transaction was changed
+// meanwhile, so that no update is lost. Watchers are notified of the changes once they are all applied.
+func (m *ConcurrentHashmap[TKey, TValue]) Begin() *ConcurrentTx[TKey, TValue] {
+	return &ConcurrentTx[TKey, TValue]{
+		m:      m,
+		writes: New[TKey, deltaEntry[TValue]]().Begin(),
+		reads:  New[TKey, *concurrentWord[TKey, TValue]](),
+	}
+}
+
+// Get the value associated with the given key, including changes of the transaction.
+func (tx *ConcurrentTx[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := tx.TryGet(key)
+	return value
+}
+
+// Try to get the value associated with the given key, including changes of the transaction.
+func (tx *ConcurrentTx[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	var zeroEntry TValue
+	if change, found := tx.writes.TryGet(key); found {
+		if change.deleted {
+			return zeroEntry, false
+		}
+		return change.value, true
+	}
+
+	entry, read := tx.reads.TryGet(key)
+	if !read {
+		var expired *concurrentWord[TKey, TValue]
+		entry, expired = tx.m.find(key, tx.m.hash(key))
+		tx.m.publishExpired(expired)
+		tx.reads.Set(key, entry)
+	}
+	if entry == nil {
+		return zeroEntry, false
+	}
+	return entry.value, true
+}
+
+// Insert or update the given value at the given key.
+func (tx *ConcurrentTx[TKey, TValue]) Set(key TKey, value TValue) {
+	tx.writes.Set(key, deltaEntry[TValue]{value: value})
+}
+
+// Remove the entry with the given key.
+func (tx *ConcurrentTx[TKey, TValue]) Delete(key TKey) {
+	tx.writes.Set(key, deltaEntry[TValue]{deleted: true})
+}
+
+// Get the current point of the transaction, later changes can be reverted with RollbackTo.
+func (tx *ConcurrentTx[TKey, TValue]) Savepoint() Savepoint {
+	return tx.writes.Savepoint()
+}
+
+// Revert the changes made since the given savepoint, the transaction stays active. See Tx.RollbackTo.
+func (tx *ConcurrentTx[TKey, TValue]) RollbackTo(savepoint Savepoint) {
+	tx.writes.RollbackTo(savepoint)
+}
+
+// Apply all changes of the transaction at once, the transaction must not be used anymore.
+//
+// Returns ErrTxConflict without applying anything if a key read by the transaction was changed since it was read.
+func (tx *ConcurrentTx[TKey, TValue]) Commit() error {
+	tx.writes.Commit()
+	changes := tx.writes.m.GetEntries()
+	for _, change := range changes {
+		if change.Value.deleted {
+			tx.m.invalidateLoad(change.Key)
+		}
+	}
+
+	events, applied := tx.apply(tx.reads.GetEntries(), changes)
+	if !applied {
+		return ErrTxConflict
+	}
+	for _, change := range changes {
+		tx.m.forgetFailure(change.Key)
+		if !change.Value.deleted {
+			tx.m.wake(change.Key, change.Value.value)
+		}
+	}
+	for _, event := range events {
+		tx.m.publish(event)
+	}
+	return nil
+}
+
+// Discard all changes of the transaction, which must not be used anymore.
+//
+// Rolling back a committed or rolled back transaction has no effect, so that Rollback can be deferred.
+func (tx *ConcurrentTx[TKey, TValue]) Rollback() {
+	tx.writes.Rollback()
+}
+
+// Record the changes in a single operation, which checks that the entries read are unchanged, and commit it.
+// Returns the events of the changes and whether they were applied.
+func (tx *ConcurrentTx[TKey, TValue]) apply(reads []KeyValue[TKey, *concurrentWord[TKey, TValue]], changes []KeyValue[TKey, deltaEntry[TValue]]) ([]Event[TKey, TValue], bool) {
+	m := tx.m
+	previous := make([]*concurrentWord[TKey, TValue], len(changes))
+retry:
+	for {
+		t := m.table.Load()
+		if t.next.Load() != nil {
+			m.resize(t)
+			continue
+		}
+
+		op := &concurrentOp[TKey, TValue]{table: t, pending: make(map[int]int)}
+		for _, read := range reads {
+			hash := m.hash(read.Key)
+			_, _, current := op.probe(read.Key, hash)
+			if op.frozen {
+				m.resize(t)
+				continue retry
+			}
+			if current == nil || current.hash != hash || current.key != read.Key || op.expired(current) {
+				current = nil
+			}
+			if current != read.Value {
+				if op.validate() {
+					return nil, false
+				}
+				continue retry
+			}
+		}
+
+		for i, change := range changes {
+			hash := m.hash(change.Key)
+			var recorded bool
+			if change.Value.deleted {
+				previous[i], recorded = m.recordRemove(op, change.Key, hash, nil)
+			} else {
+				entry := &concurrentWord[TKey, TValue]{key: change.Key, hash: hash, value: change.Value.value}
+				previous[i], recorded = m.recordInsert(op, entry, false)
+			}
+			if !recorded {
+				if op.frozen {
+					m.resize(t)
+				}
+				continue retry
+			}
+		}
+
+		if len(op.writes) == 0 && op.validate() || len(op.writes) > 0 && op.commit() {
+			m.length.Add(op.added)
+			return tx.events(op, changes, previous), true
+		}
+	}
+}
+
+// Build the events of the applied changes, given the previous entries of their keys.
+func (tx *ConcurrentTx[TKey, TValue]) events(op *concurrentOp[TKey, TValue], changes []KeyValue[TKey, deltaEntry[TValue]], previous []*concurrentWord[TKey, TValue]) []Event[TKey, TValue] {
+	events := make([]Event[TKey, TValue], 0, len(changes))
+	for i, change := range changes {
+		var old TValue
+		existed := false
+		if entry := previous[i]; entry != nil {
+			if op.expired(entry) {
+				events = append(events, Event[TKey, TValue]{Kind: EventExpire, Key: entry.key, OldValue: entry.value, Existed: true})
+			} else {
+				old, existed = entry.value, true
+			}
+		}
+		if !change.Value.deleted {
+			events = append(events, Event[TKey, TValue]{Kind: EventSet, Key: change.Key, OldValue: old, Existed: existed, NewValue: change.Value.value})
+		} else if existed {
+			events = append(events, Event[TKey, TValue]{Kind: EventDelete, Key: change.Key, OldValue: old, Existed: true})
+		}
+	}
+	return events
+}
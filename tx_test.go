@@ -0,0 +1,326 @@
+package hashmap
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func sortedEntries[TValue any](entries []KeyValue[int, TValue]) []KeyValue[int, TValue] {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+func TestTx(t *testing.T) {
+	for _, l := range layouts {
+		m := New(WithLayout[int, int](l.layout), WithInitialCapacity[int, int](8))
+		for i := range 10 {
+			m.Set(i, i)
+		}
+		initial := sortedEntries(m.GetEntries())
+
+		tx := m.Begin()
+		tx.Set(0, 100)
+		tx.Delete(1)
+		tx.Delete(1000)
+		if tx.Get(0) != 100 {
+			t.Errorf("transaction must read its own writes for layout=%s", l.name)
+		}
+		if _, found := tx.TryGet(1); found {
+			t.Errorf("deleted key found in transaction for layout=%s", l.name)
+		}
+
+		savepoint := tx.Savepoint()
+		for i := range 100 {
+			tx.Set(i, -i) // Grows the storage
+		}
+		tx.Delete(5)
+		tx.RollbackTo(savepoint)
+		if m.Len() != 9 || m.Get(0) != 100 || m.Get(2) != 2 || m.Get(5) != 5 {
+			t.Errorf("invalid content after rollback to savepoint for layout=%s: %v", l.name, sortedEntries(m.GetEntries()))
+		}
+
+		tx.Set(20, 20)
+		tx.Rollback()
+		tx.Rollback()
+		if entries := sortedEntries(m.GetEntries()); !reflect.DeepEqual(entries, initial) {
+			t.Errorf("invalid content after rollback for layout=%s. expected=%v, got=%v", l.name, initial, entries)
+		}
+
+		tx = m.Begin()
+		tx.Set(20, 20)
+		tx.Delete(0)
+		tx.Commit()
+		tx.Rollback()
+		if m.Len() != 10 || m.Get(20) != 20 || m.Get(0) != 0 {
+			t.Errorf("invalid content after commit for layout=%s", l.name)
+		}
+	}
+}
+
+func TestTxDone(t *testing.T) {
+	tx := New[int, int]().Begin()
+	tx.Commit()
+	defer func() {
+		if recover() == nil {
+			t.Error("using a committed transaction must panic")
+		}
+	}()
+	tx.Set(1, 1)
+}
+
+func TestReadMostlyTx(t *testing.T) {
+	m := NewReadMostly[int, int]()
+	for i := range 10 {
+		m.Set(i, i)
+	}
+
+	tx := m.Begin()
+	tx.Set(0, 100)
+	tx.Delete(1)
+	savepoint := tx.Savepoint()
+	tx.Set(2, 200)
+	tx.Set(1, 100)
+	tx.RollbackTo(savepoint)
+	if value, found := tx.TryGet(1); found {
+		t.Errorf("deleted key found in transaction: %d", value)
+	}
+	if tx.Get(0) != 100 || tx.Get(2) != 2 {
+		t.Error("transaction must read its own writes")
+	}
+	if m.Get(0) != 0 || m.Get(1) != 1 {
+		t.Error("changes published before commit")
+	}
+
+	// Readers see either none or all of the changes
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			entries := sortedEntries(m.GetEntries())
+			if len(entries) != 10 && len(entries) != 9 || len(entries) == 9 && entries[0].Value != 100 || len(entries) == 10 && entries[0].Value != 0 {
+				t.Errorf("partial transaction seen: %v", entries)
+				return
+			}
+		}
+	}()
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected commit error: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if m.Len() != 9 || m.Get(0) != 100 {
+		t.Errorf("invalid content after commit: %v", sortedEntries(m.GetEntries()))
+	}
+
+	tx = m.Begin()
+	tx.Set(50, 50)
+	tx.Rollback()
+	if _, found := m.TryGet(50); found {
+		t.Error("rolled back change published")
+	}
+}
+
+func TestReadMostlyTxConflict(t *testing.T) {
+	m := NewReadMostly[int, int]()
+	m.Set(0, 0)
+	m.Set(1, 1)
+
+	// Lost update: the key read is changed before the commit
+	tx := m.Begin()
+	tx.Set(0, tx.Get(0)+1)
+	m.Set(0, 10)
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Errorf("invalid commit error. expected=%v, got=%v", ErrTxConflict, err)
+	}
+	if m.Get(0) != 10 {
+		t.Errorf("conflicting transaction published: %v", sortedEntries(m.GetEntries()))
+	}
+
+	// Changes of other keys don't conflict
+	tx = m.Begin()
+	tx.Set(0, tx.Get(0)+1)
+	tx.Set(2, 2) // Not read
+	m.Set(1, 11)
+	m.Set(2, 12)
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected commit error: %v", err)
+	}
+	if m.Get(0) != 11 || m.Get(1) != 11 || m.Get(2) != 2 {
+		t.Errorf("invalid content after commit: %v", sortedEntries(m.GetEntries()))
+	}
+}
+
+func TestConcurrentTx(t *testing.T) {
+	m := NewConcurrent[int, int](WithInitialCapacity[int, int](4))
+	for i := range 10 {
+		m.Set(i, i)
+	}
+	events, cancel := m.Watch(nil, WithWatchBuffer(1000))
+	defer cancel()
+
+	tx := m.Begin()
+	tx.Set(0, 100)
+	tx.Delete(1)
+	savepoint := tx.Savepoint()
+	tx.Set(2, 200)
+	tx.Set(1, 100)
+	tx.RollbackTo(savepoint)
+	for i := range 100 {
+		tx.Set(i+10, i+10) // Grows the table on commit
+	}
+	if value, found := tx.TryGet(1); found {
+		t.Errorf("deleted key found in transaction: %d", value)
+	}
+	if tx.Get(0) != 100 || tx.Get(2) != 2 || tx.Get(50) != 50 {
+		t.Error("transaction must read its own writes")
+	}
+	if m.Get(0) != 0 || m.Get(1) != 1 || m.Len() != 10 {
+		t.Error("changes applied before commit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected commit error: %v", err)
+	}
+	tx.Rollback()
+	if m.Len() != 109 || m.Get(0) != 100 || m.Get(109) != 109 {
+		t.Errorf("invalid content after commit: %v", sortedEntries(m.GetEntries()))
+	}
+	if _, found := m.TryGet(1); found {
+		t.Error("deleted key found after commit")
+	}
+	if len(events) != 102 {
+		t.Errorf("invalid number of events. expected=%d, got=%d", 102, len(events))
+	}
+
+	tx = m.Begin()
+	tx.Set(500, 500)
+	tx.Rollback()
+	if _, found := m.TryGet(500); found {
+		t.Error("rolled back change applied")
+	}
+}
+
+func TestConcurrentTxConflict(t *testing.T) {
+	m := NewConcurrent[int, int]()
+	m.Set(0, 0)
+	m.Set(1, 1)
+
+	tx := m.Begin()
+	tx.Set(0, tx.Get(0)+1)
+	m.Set(0, 10)
+	if tx.Get(0) != 1 {
+		t.Error("transaction must read its own writes")
+	}
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Errorf("invalid commit error. expected=%v, got=%v", ErrTxConflict, err)
+	}
+	if m.Get(0) != 10 {
+		t.Errorf("conflicting transaction applied: %v", sortedEntries(m.GetEntries()))
+	}
+
+	// A missing key read is set meanwhile
+	tx = m.Begin()
+	if _, found := tx.TryGet(2); !found {
+		tx.Set(2, 2)
+	}
+	m.Set(2, 12)
+	if err := tx.Commit(); !errors.Is(err, ErrTxConflict) {
+		t.Errorf("invalid commit error. expected=%v, got=%v", ErrTxConflict, err)
+	}
+
+	// Changes of other keys don't conflict
+	tx = m.Begin()
+	tx.Set(0, tx.Get(0)+1)
+	m.Set(1, 11)
+	m.Delete(2)
+	if err := tx.Commit(); err != nil {
+		t.Errorf("unexpected commit error: %v", err)
+	}
+	if m.Get(0) != 11 || m.Get(1) != 11 {
+		t.Errorf("invalid content after commit: %v", sortedEntries(m.GetEntries()))
+	}
+}
+
+// Transactions move units between keys, inserting and deleting them: readers must always see the same total.
+func TestConcurrentTxAtomicity(t *testing.T) {
+	const keys, total = 64, 800
+	m := NewConcurrent[int, int](WithInitialCapacity[int, int](4))
+	for i := range 8 {
+		m.Set(i, total/8)
+	}
+
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for range 2 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				sum := 0
+				for _, entry := range m.GetEntries() {
+					sum += entry.Value
+				}
+				if sum != total {
+					t.Errorf("partial transaction seen. expected total=%d, got=%d", total, sum)
+					return
+				}
+			}
+		}()
+	}
+
+	var writers sync.WaitGroup
+	for w := range 4 {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for i := range 300 {
+				from, to := (w*31+i*7)%keys, (w*17+i*13+1)%keys
+				for {
+					tx := m.Begin()
+					amount := tx.Get(from)
+					if amount == 0 || from == to {
+						tx.Rollback()
+						break
+					}
+					tx.Delete(from)
+					tx.Set(to, tx.Get(to)+amount)
+					err := tx.Commit()
+					if err == nil {
+						break
+					}
+					if !errors.Is(err, ErrTxConflict) {
+						t.Errorf("unexpected commit error: %v", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	readers.Wait()
+
+	sum := 0
+	for _, entry := range m.GetEntries() {
+		sum += entry.Value
+	}
+	if sum != total || m.Len() != len(m.GetEntries()) {
+		t.Errorf("invalid content after transactions. total=%d, length=%d: %v", sum, m.Len(), sortedEntries(m.GetEntries()))
+	}
+}
@@ -0,0 +1,225 @@
+package hashmap
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Returned when reading a version which was collected, or which doesn't exist yet.
+var ErrVersionUnavailable = errors.New("hashmap: version is not available")
+
+// Value of a key from a version, until the next record of the key.
+type versionRecord[TValue any] struct {
+	version uint64
+	value   TValue
+	deleted bool
+}
+
+// Records of a key, ordered by version.
+type versionHistory[TValue any] struct {
+	records []versionRecord[TValue]
+}
+
+// Write of a key, kept until the version is collected.
+type versionedWrite[TKey any] struct {
+	version uint64
+	key     TKey
+}
+
+// Hashmap keeping the previous values of its keys, for point-in-time reads.
+//
+// Every Set and Delete produces a new version, starting from version 0 which is empty. Each key has a history of
+// its values, ordered by version. Versions older than the retention are collected as writes go: only the
+// value each key had at the oldest retained version is kept, along with newer ones.
+type VersionedHashmap[TKey comparable, TValue any] struct {
+	histories  *Hashmap[TKey, *versionHistory[TValue]]
+	writes     []versionedWrite[TKey] // Writes of retained versions, the oldest ones first
+	writesHead int
+	version    uint64
+	oldest     uint64 // Oldest readable version
+	retention  uint64
+	length     int
+}
+
+// Instanciate a new versioned hashmap, keeping the given number of versions readable (all versions if 0).
+//
+// The load percentage, initial capacity, hash function, seed and layout configurations are supported, other ones are ignored.
+func NewVersioned[TKey comparable, TValue any](retention uint64, config ...HashMapConfig[TKey, TValue]) *VersionedHashmap[TKey, TValue] {
+	options := Hashmap[TKey, TValue]{
+		loadFactor:      defaultLoadFactor,
+		initialCapacity: defaultInitialCapacity,
+		hashSeed:        hasher.GenerateSeed(),
+	}
+	for _, configFunc := range config {
+		configFunc(&options)
+	}
+
+	historiesConfig := func(histories *Hashmap[TKey, *versionHistory[TValue]]) {
+		histories.loadFactor = options.loadFactor
+		histories.initialCapacity = options.initialCapacity
+		histories.hashFunc = options.hashFunc
+		histories.hashSeed = options.hashSeed
+		histories.layout = options.layout
+	}
+	return &VersionedHashmap[TKey, TValue]{
+		histories: New(historiesConfig),
+		retention: retention,
+	}
+}
+
+// Get the current value associated with the given key. A default value is returned if the key doesn't exist.
+func (m *VersionedHashmap[TKey, TValue]) Get(key TKey) TValue {
+	value, _ := m.TryGet(key)
+	return value
+}
+
+// Try to get the current value associated with the given key.
+func (m *VersionedHashmap[TKey, TValue]) TryGet(key TKey) (TValue, bool) {
+	history, found := m.histories.TryGet(key)
+	if !found {
+		var zeroEntry TValue
+		return zeroEntry, false
+	}
+	last := history.records[len(history.records)-1]
+	return last.value, !last.deleted
+}
+
+// Try to get the value associated with the given key at the given version.
+//
+// ErrVersionUnavailable is returned if the version was collected or doesn't exist yet.
+func (m *VersionedHashmap[TKey, TValue]) GetAt(key TKey, version uint64) (TValue, bool, error) {
+	var zeroEntry TValue
+	if version < m.oldest || version > m.version {
+		return zeroEntry, false, ErrVersionUnavailable
+	}
+	history, found := m.histories.TryGet(key)
+	if !found {
+		return zeroEntry, false, nil
+	}
+	record, found := history.at(version)
+	if !found || record.deleted {
+		return zeroEntry, false, nil
+	}
+	return record.value, true, nil
+}
+
+// Get an iterator over the entries of the given version, in no particular order. Iteration stops when fn returns false.
+//
+// The iterator reads the hashmap when called, it panics if the version was collected meanwhile.
+// ErrVersionUnavailable is returned if the version was collected or doesn't exist yet.
+func (m *VersionedHashmap[TKey, TValue]) SnapshotAt(version uint64) (func(fn func(key TKey, value TValue) bool), error) {
+	if version < m.oldest || version > m.version {
+		return nil, ErrVersionUnavailable
+	}
+	return func(fn func(key TKey, value TValue) bool) {
+		if version < m.oldest {
+			panic("hashmap: snapshot version was collected")
+		}
+		// Iterate over a copy of the histories, fn may write the hashmap
+		for _, entry := range m.histories.GetEntries() {
+			record, found := entry.Value.at(version)
+			if found && !record.deleted && !fn(entry.Key, record.value) {
+				return
+			}
+		}
+	}, nil
+}
+
+// Insert or update the given value at the given key, returns the new version.
+func (m *VersionedHashmap[TKey, TValue]) Set(key TKey, value TValue) uint64 {
+	m.version++
+	history, found := m.histories.TryGet(key)
+	if !found {
+		history = &versionHistory[TValue]{}
+		m.histories.Set(key, history)
+	}
+	if len(history.records) == 0 || history.records[len(history.records)-1].deleted {
+		m.length++
+	}
+	history.records = append(history.records, versionRecord[TValue]{version: m.version, value: value})
+	m.writes = append(m.writes, versionedWrite[TKey]{version: m.version, key: key})
+	m.collect()
+	return m.version
+}
+
+// Remove the entry with the given key from the hashmap, returns the new version.
+func (m *VersionedHashmap[TKey, TValue]) Delete(key TKey) uint64 {
+	m.version++
+	if history, found := m.histories.TryGet(key); found && !history.records[len(history.records)-1].deleted {
+		m.length--
+		history.records = append(history.records, versionRecord[TValue]{version: m.version, deleted: true})
+		m.writes = append(m.writes, versionedWrite[TKey]{version: m.version, key: key})
+	}
+	m.collect()
+	return m.version
+}
+
+// Get the current version.
+func (m *VersionedHashmap[TKey, TValue]) Version() uint64 {
+	return m.version
+}
+
+// Get the oldest version which can be read.
+func (m *VersionedHashmap[TKey, TValue]) OldestVersion() uint64 {
+	return m.oldest
+}
+
+// Get the number of entries stored in the hashmap, at the current version.
+func (m *VersionedHashmap[TKey, TValue]) Len() int {
+	return m.length
+}
+
+// Collect the versions older than the retention: drop the records of keys written at these versions,
+// which were replaced before the oldest retained version.
+func (m *VersionedHashmap[TKey, TValue]) collect() {
+	if m.retention == 0 || m.version < m.retention {
+		return
+	}
+	m.oldest = m.version - m.retention + 1
+	for m.writesHead < len(m.writes) && m.writes[m.writesHead].version <= m.oldest {
+		m.prune(m.writes[m.writesHead].key)
+		m.writes[m.writesHead] = versionedWrite[TKey]{}
+		m.writesHead++
+	}
+	if m.writesHead > len(m.writes)/2 {
+		// Release the collected writes
+		m.writes = append(m.writes[:0], m.writes[m.writesHead:]...)
+		m.writesHead = 0
+	}
+}
+
+// Drop the records of the key which are not visible from the oldest version anymore.
+func (m *VersionedHashmap[TKey, TValue]) prune(key TKey) {
+	history, found := m.histories.TryGet(key)
+	if !found {
+		return
+	}
+	// Keep the record visible from the oldest version, unless it's a deletion
+	visible := 0
+	for visible+1 < len(history.records) && history.records[visible+1].version <= m.oldest {
+		visible++
+	}
+	if history.records[visible].deleted && history.records[visible].version <= m.oldest {
+		visible++
+	}
+	if visible == len(history.records) {
+		m.histories.Delete(key)
+		return
+	}
+	clear(history.records[:visible])
+	history.records = history.records[visible:]
+}
+
+// Get the record visible from the given version.
+func (h *versionHistory[TValue]) at(version uint64) (versionRecord[TValue], bool) {
+	index := sort.Search(len(h.records), func(i int) bool {
+		return h.records[i].version > version
+	})
+	if index == 0 {
+		var zeroRecord versionRecord[TValue]
+		return zeroRecord, false
+	}
+	return h.records[index-1], true
+}
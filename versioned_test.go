@@ -0,0 +1,102 @@
+package hashmap
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+// Apply random writes, and compare every retained version with a copy of a native map taken at that version.
+func TestVersioned(t *testing.T) {
+	for _, retention := range []uint64{0, 1, 50} {
+		rng := rand.New(rand.NewSource(1))
+		m := NewVersioned[int, int](retention, WithInitialCapacity[int, int](8))
+		expected := []map[int]int{{}} // Content at each version
+		for i := range 2_000 {
+			content := maps.Clone(expected[len(expected)-1])
+			key := rng.Intn(100)
+			var version uint64
+			if rng.Intn(3) == 0 {
+				version = m.Delete(key)
+				delete(content, key)
+			} else {
+				version = m.Set(key, i)
+				content[key] = i
+			}
+			expected = append(expected, content)
+			if version != uint64(len(expected)-1) || m.Version() != version {
+				t.Fatalf("invalid version. expected=%d, got=%d", len(expected)-1, version)
+			}
+			if m.Len() != len(content) {
+				t.Fatalf("invalid length. expected=%d, got=%d", len(content), m.Len())
+			}
+		}
+
+		oldest := uint64(0)
+		if retention != 0 {
+			oldest = m.Version() - retention + 1
+		}
+		if m.OldestVersion() != oldest {
+			t.Errorf("invalid oldest version for retention=%d. expected=%d, got=%d", retention, oldest, m.OldestVersion())
+		}
+		for version, content := range expected {
+			_, _, err := m.GetAt(0, uint64(version))
+			if uint64(version) < oldest {
+				if err != ErrVersionUnavailable {
+					t.Fatalf("collected version %d must be unavailable, got err=%v", version, err)
+				}
+				continue
+			}
+			for key := range 100 {
+				value, found, err := m.GetAt(key, uint64(version))
+				expectedValue, expectedFound := content[key]
+				if err != nil || value != expectedValue || found != expectedFound {
+					t.Fatalf("invalid lookup for retention=%d, version=%d, key=%d. expected=(%d, %t), got=(%d, %t, %v)", retention, version, key, expectedValue, expectedFound, value, found, err)
+				}
+			}
+			snapshot, err := m.SnapshotAt(uint64(version))
+			if err != nil {
+				t.Fatalf("snapshot of version %d failed: %v", version, err)
+			}
+			entries := map[int]int{}
+			snapshot(func(key, value int) bool {
+				entries[key] = value
+				return true
+			})
+			if !maps.Equal(entries, content) {
+				t.Fatalf("invalid snapshot for retention=%d, version=%d. expected=%v, got=%v", retention, version, content, entries)
+			}
+		}
+
+		if _, _, err := m.GetAt(0, m.Version()+1); err != ErrVersionUnavailable {
+			t.Errorf("future version must be unavailable, got err=%v", err)
+		}
+		if retention != 0 {
+			// Only the records of retained versions are kept, plus one per key
+			records := 0
+			for _, entry := range m.histories.GetEntries() {
+				records += len(entry.Value.records)
+			}
+			if records > 100+int(retention) {
+				t.Errorf("versions not collected for retention=%d. got %d records", retention, records)
+			}
+		}
+	}
+}
+
+func TestVersionedSnapshotCollected(t *testing.T) {
+	m := NewVersioned[string, int](2)
+	m.Set("a", 1)
+	snapshot, err := m.SnapshotAt(1)
+	if err != nil {
+		t.Fatalf("snapshot failed: %v", err)
+	}
+	m.Set("a", 2)
+	m.Set("a", 3)
+	defer func() {
+		if recover() == nil {
+			t.Error("iterating over a collected version must panic")
+		}
+	}()
+	snapshot(func(key string, value int) bool { return true })
+}
@@ -0,0 +1,90 @@
+package hashmap
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Goroutines waiting for a ConcurrentHashmap key to be set.
+type keyWaiters[TValue any] struct {
+	done    chan struct{} // Closed once value is set
+	value   TValue
+	waiters int // Guarded by the wait mutex
+}
+
+// State of WaitFor, the zero value is ready to use.
+type waitState[TKey comparable, TValue any] struct {
+	mu      sync.Mutex
+	keys    Hashmap[TKey, *keyWaiters[TValue]]
+	waiting atomic.Int64 // Number of waited keys, allows writes to skip the mutex when nobody waits
+}
+
+// Get the value associated with the given key, waiting for it to be set if it doesn't exist.
+//
+// Waiting goroutines are blocked on a channel, which is closed by the next write of the key, so all of them
+// are woken up at once with the written value. The context error is returned if the context is done first.
+func (m *ConcurrentHashmap[TKey, TValue]) WaitFor(ctx context.Context, key TKey) (TValue, error) {
+	if value, found := m.TryGet(key); found {
+		return value, nil
+	}
+
+	state := &m.wait
+	state.mu.Lock()
+	w, found := state.keys.TryGet(key)
+	if !found {
+		w = &keyWaiters[TValue]{done: make(chan struct{})}
+		state.keys.Set(key, w)
+		state.waiting.Store(int64(state.keys.Len()))
+	}
+	// Check again once registered: writes either stored the key before this lookup, or see the waiters afterwards
+	value, found, expired := m.lookup(key)
+	if found {
+		m.release(key, w, value)
+		state.mu.Unlock()
+		return value, nil
+	}
+	w.waiters++
+	state.mu.Unlock()
+	// Outside of the lock, watchers may block writers
+	m.publishExpired(expired)
+
+	select {
+	case <-w.done:
+		return w.value, nil
+	case <-ctx.Done():
+		state.mu.Lock()
+		w.waiters--
+		if w.waiters == 0 {
+			if current, _ := state.keys.TryGet(key); current == w {
+				state.keys.Delete(key)
+				state.waiting.Store(int64(state.keys.Len()))
+			}
+		}
+		state.mu.Unlock()
+		var zeroEntry TValue
+		return zeroEntry, ctx.Err()
+	}
+}
+
+// Wake up the goroutines waiting for the given key, if any. Must be called after the value is stored.
+func (m *ConcurrentHashmap[TKey, TValue]) wake(key TKey, value TValue) {
+	state := &m.wait
+	if state.waiting.Load() == 0 {
+		return
+	}
+	state.mu.Lock()
+	if w, found := state.keys.TryGet(key); found {
+		m.release(key, w, value)
+	}
+	state.mu.Unlock()
+}
+
+// Unregister waiters of a key and hand them the value. Must be called with the wait mutex held.
+func (m *ConcurrentHashmap[TKey, TValue]) release(key TKey, w *keyWaiters[TValue], value TValue) {
+	state := &m.wait
+	state.keys.Delete(key)
+	state.waiting.Store(int64(state.keys.Len()))
+	w.value = value
+	close(w.done)
+}
@@ -0,0 +1,151 @@
+package hashmap
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+// Wait until the given number of goroutines wait for the key.
+func waitForKeyWaiters[TKey comparable, TValue any](m *ConcurrentHashmap[TKey, TValue], key TKey, waiters int) {
+	for {
+		m.wait.mu.Lock()
+		w, found := m.wait.keys.TryGet(key)
+		current := 0
+		if found {
+			current = w.waiters
+		}
+		m.wait.mu.Unlock()
+		if current == waiters {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	const goroutines = 50
+	m := NewConcurrent[string, int]()
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := m.WaitFor(context.Background(), "key")
+			if value != 1 || err != nil {
+				t.Errorf("invalid result. expected=(1, nil), got=(%d, %v)", value, err)
+			}
+		}()
+	}
+	waitForKeyWaiters(m, "key", goroutines)
+	m.Set("key", 1)
+	wg.Wait()
+
+	if m.wait.waiting.Load() != 0 {
+		t.Errorf("waiters not unregistered. got=%d waited keys", m.wait.waiting.Load())
+	}
+
+	// Existing keys are returned directly
+	value, err := m.WaitFor(context.Background(), "key")
+	if value != 1 || err != nil {
+		t.Errorf("invalid result. expected=(1, nil), got=(%d, %v)", value, err)
+	}
+
+	// Values loaded by GetOrCompute wake up waiters
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if value, _ := m.WaitFor(context.Background(), "computed"); value != 2 {
+			t.Errorf("invalid computed value. expected=2, got=%d", value)
+		}
+	}()
+	waitForKeyWaiters(m, "computed", 1)
+	m.GetOrCompute(context.Background(), "computed", func(ctx context.Context) (int, error) { return 2, nil })
+	<-done
+}
+
+func TestWaitForCancellation(t *testing.T) {
+	m := NewConcurrent[string, int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.WaitFor(ctx, "key"); err != context.DeadlineExceeded {
+		t.Errorf("invalid error. expected=%v, got=%v", context.DeadlineExceeded, err)
+	}
+	if m.wait.keys.Len() != 0 || m.wait.waiting.Load() != 0 {
+		t.Errorf("canceled waiter not unregistered. got=%d waited keys", m.wait.keys.Len())
+	}
+}
+
+// Start waiters and writers of the same keys at the same time: no wakeup must be lost.
+func TestWaitForConcurrentSet(t *testing.T) {
+	const keys = 2_000
+	m := NewConcurrent[string, int]()
+	var wg sync.WaitGroup
+	for i := range keys {
+		key := strconv.Itoa(i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if value, err := m.WaitFor(ctx, key); value != i || err != nil {
+				t.Errorf("invalid result for key=%s. expected=(%d, nil), got=(%d, %v)", key, i, value, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			m.Set(key, i)
+		}()
+	}
+	wg.Wait()
+}
+
+// A watcher blocking writers can write to the hashmap: WaitFor must not publish expirations while holding the wait lock.
+func TestWaitForBlockingWatcher(t *testing.T) {
+	var hashes atomic.Int32
+	var m *ConcurrentHashmap[string, int]
+	hashFunc := hasher.GetHashFunc[string]()
+	m = NewConcurrent(WithHashFunc[string, int](func(key, seed uintptr) uintptr {
+		hash := hashFunc(key, seed) // Before any call growing the stack, which moves the key
+		if hashes.Add(-1) == 0 {
+			// Second lookup of WaitFor, done with the wait lock held: the key is set meanwhile but expired,
+			// and the watcher is busy with a write waiting for the lock
+			m.store("key", 1, time.Now().Add(-time.Second).UnixNano(), false, false)
+			m.Delete("trigger")
+		}
+		return hash
+	}))
+	m.Set("trigger", 0)
+
+	events, cancel := m.Watch(func(key string) bool { return key != "other" }, WithWatchBuffer(0), WithWatchPolicy(BlockWriters))
+	defer cancel()
+	go func() {
+		for event := range events {
+			if event.Key == "trigger" {
+				m.Set("other", 1) // Wakes waiters, which takes the wait lock
+			}
+		}
+	}()
+
+	hashes.Store(2)
+	done := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := m.WaitFor(ctx, "key")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("invalid error. expected=%v, got=%v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock between WaitFor and the watcher")
+	}
+}
@@ -0,0 +1,271 @@
+package hashmap
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultWatchBuffer = 64
+
+// Kind of change of a hashmap.
+type EventKind uint8
+
+const (
+	// An entry was inserted or updated.
+	EventSet EventKind = iota
+	// An entry was removed.
+	EventDelete
+	// All entries were removed, the event has no key and is sent to all watchers.
+	EventClear
+	// An entry set with a TTL expired and was removed.
+	EventExpire
+)
+
+// Change of a hashmap content.
+type Event[TKey, TValue any] struct {
+	Kind     EventKind
+	Key      TKey
+	OldValue TValue // Value before the change, only set if Existed
+	Existed  bool   // Whether the key existed before the change
+	NewValue TValue // Value after the change, only set for EventSet
+}
+
+// Behavior of a watch whose consumer doesn't keep up with changes, once its events buffer is full.
+type WatchPolicy uint8
+
+const (
+	// Discard new events (default), writers are never slowed down.
+	DropEvents WatchPolicy = iota
+	// Block writers until the consumer frees room in the buffer. Events are sent once no internal lock is held,
+	// so the consumer can write to the hashmap. Reads removing an expired entry may block too.
+	BlockWriters
+	// Merge the pending events of each key: the consumer gets the latest change of a key, with the value it had
+	// before the first merged change. Changes cancelling each other, like an insertion followed by a deletion, are dropped.
+	CoalesceEvents
+)
+
+// Options of ConcurrentHashmap.Watch.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	buffer int
+	policy WatchPolicy
+}
+
+// Specify the size of the events channel buffer, 64 by default.
+func WithWatchBuffer(size int) WatchOption {
+	return func(options *watchOptions) {
+		options.buffer = max(size, 0)
+	}
+}
+
+// Specify the behavior of the watch once its buffer is full, see WatchPolicy.
+func WithWatchPolicy(policy WatchPolicy) WatchOption {
+	return func(options *watchOptions) {
+		options.policy = policy
+	}
+}
+
+// Subscription to the changes of a ConcurrentHashmap.
+type watcher[TKey comparable, TValue any] struct {
+	filter func(TKey) bool
+	policy WatchPolicy
+	events chan Event[TKey, TValue]
+	done   chan struct{} // Closed on cancellation
+
+	// Senders hold the read lock, so that the events channel is only closed once no sender can use it anymore
+	sendMu sync.RWMutex
+	closed bool
+
+	// Events waiting to be forwarded to the events channel, with the CoalesceEvents policy
+	mu          sync.Mutex
+	pending     []pendingEvent[TKey, TValue]
+	pendingHead int
+	pendingKeys Hashmap[TKey, int] // Index of the pending event of each key
+	signal      chan struct{}
+}
+
+type pendingEvent[TKey, TValue any] struct {
+	event   Event[TKey, TValue]
+	dropped bool // Merged changes cancelled each other
+}
+
+// Subscriptions of a ConcurrentHashmap, the zero value is ready to use.
+type watchState[TKey comparable, TValue any] struct {
+	mu       sync.Mutex // Serializes subscriptions updates
+	watchers atomic.Pointer[[]*watcher[TKey, TValue]]
+}
+
+// Subscribe to the changes of the hashmap, whose key is accepted by filter (all keys when nil).
+//
+// Events are sent on the returned channel, buffered according to the options. The cancel function stops the
+// subscription and closes the channel. Events of the writes made by a goroutine are delivered in order, but
+// concurrent writes of the same key may be delivered in another order than they were applied.
+func (m *ConcurrentHashmap[TKey, TValue]) Watch(filter func(TKey) bool, options ...WatchOption) (<-chan Event[TKey, TValue], func()) {
+	watchOptions := watchOptions{buffer: defaultWatchBuffer}
+	for _, option := range options {
+		option(&watchOptions)
+	}
+
+	w := &watcher[TKey, TValue]{
+		filter: filter,
+		policy: watchOptions.policy,
+		events: make(chan Event[TKey, TValue], watchOptions.buffer),
+		done:   make(chan struct{}),
+	}
+	if w.policy == CoalesceEvents {
+		w.signal = make(chan struct{}, 1)
+		go w.forward()
+	}
+
+	state := &m.watch
+	state.mu.Lock()
+	var watchers []*watcher[TKey, TValue]
+	if current := state.watchers.Load(); current != nil {
+		watchers = slices.Clone(*current)
+	}
+	watchers = append(watchers, w)
+	state.watchers.Store(&watchers)
+	state.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			state.mu.Lock()
+			watchers := slices.DeleteFunc(slices.Clone(*state.watchers.Load()), func(other *watcher[TKey, TValue]) bool {
+				return other == w
+			})
+			if len(watchers) == 0 {
+				state.watchers.Store(nil)
+			} else {
+				state.watchers.Store(&watchers)
+			}
+			state.mu.Unlock()
+			w.close()
+		})
+	}
+	return w.events, cancel
+}
+
+// Send an event to the watchers interested in its key.
+func (m *ConcurrentHashmap[TKey, TValue]) publish(event Event[TKey, TValue]) {
+	watchers := m.watch.watchers.Load()
+	if watchers == nil {
+		return
+	}
+	for _, w := range *watchers {
+		if w.filter == nil || event.Kind == EventClear || w.filter(event.Key) {
+			w.send(event)
+		}
+	}
+}
+
+// Deliver an event according to the watcher policy.
+func (w *watcher[TKey, TValue]) send(event Event[TKey, TValue]) {
+	if w.policy == CoalesceEvents {
+		w.enqueue(event)
+		return
+	}
+
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+	if w.closed {
+		return
+	}
+	if w.policy == BlockWriters {
+		select {
+		case w.events <- event:
+		case <-w.done:
+		}
+		return
+	}
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// Stop the subscription and close the events channel.
+func (w *watcher[TKey, TValue]) close() {
+	close(w.done) // Unblock senders
+	if w.policy == CoalesceEvents {
+		return // The forwarding goroutine closes the channel
+	}
+	w.sendMu.Lock()
+	w.closed = true
+	close(w.events)
+	w.sendMu.Unlock()
+}
+
+// Add an event to the pending ones, merging it with the pending event of the same key.
+func (w *watcher[TKey, TValue]) enqueue(event Event[TKey, TValue]) {
+	w.mu.Lock()
+	switch index, found := w.pendingKeys.TryGet(event.Key); {
+	case event.Kind == EventClear:
+		// Previous changes are cleared anyway
+		w.pending = append(w.pending[:0], pendingEvent[TKey, TValue]{event: event})
+		w.pendingHead = 0
+		w.pendingKeys.Clear()
+	case found:
+		pending := &w.pending[index]
+		event.OldValue, event.Existed = pending.event.OldValue, pending.event.Existed
+		pending.event = event
+		pending.dropped = event.Kind != EventSet && !event.Existed
+	default:
+		w.pendingKeys.Set(event.Key, len(w.pending))
+		w.pending = append(w.pending, pendingEvent[TKey, TValue]{event: event})
+	}
+	w.mu.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Forward pending events to the events channel, until the watch is canceled.
+func (w *watcher[TKey, TValue]) forward() {
+	defer close(w.events)
+	for {
+		select {
+		case <-w.signal:
+		case <-w.done:
+			return
+		}
+		for {
+			event, found := w.dequeue()
+			if !found {
+				break
+			}
+			select {
+			case w.events <- event:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}
+
+// Pop the oldest pending event.
+func (w *watcher[TKey, TValue]) dequeue() (Event[TKey, TValue], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.pendingHead < len(w.pending) {
+		pending := w.pending[w.pendingHead]
+		if pending.event.Kind != EventClear {
+			w.pendingKeys.Delete(pending.event.Key)
+		}
+		w.pendingHead++
+		if w.pendingHead == len(w.pending) {
+			clear(w.pending)
+			w.pending = w.pending[:0]
+			w.pendingHead = 0
+		}
+		if !pending.dropped {
+			return pending.event, true
+		}
+	}
+	var zeroEvent Event[TKey, TValue]
+	return zeroEvent, false
+}
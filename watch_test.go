@@ -0,0 +1,221 @@
+package hashmap
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func receive[TKey, TValue any](t *testing.T, events <-chan Event[TKey, TValue]) Event[TKey, TValue] {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		panic("unreachable")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	m := NewConcurrent[int, string]()
+	events, cancel := m.Watch(func(key int) bool { return key%2 == 0 })
+
+	m.Set(2, "a")
+	m.Set(1, "filtered")
+	m.Set(2, "b")
+	m.Delete(3)
+	m.Delete(2)
+	m.Delete(2)
+	m.SetWithTTL(4, "c", -time.Second)
+	m.Get(4) // Expires
+	m.Clear()
+
+	expected := []Event[int, string]{
+		{Kind: EventSet, Key: 2, NewValue: "a"},
+		{Kind: EventSet, Key: 2, OldValue: "a", Existed: true, NewValue: "b"},
+		{Kind: EventDelete, Key: 2, OldValue: "b", Existed: true},
+		{Kind: EventSet, Key: 4, NewValue: "c"},
+		{Kind: EventExpire, Key: 4, OldValue: "c", Existed: true},
+		{Kind: EventClear}, // Not filtered
+	}
+	for _, expectedEvent := range expected {
+		if event := receive(t, events); event != expectedEvent {
+			t.Errorf("invalid event. expected=%+v, got=%+v", expectedEvent, event)
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event: %+v", event)
+	default:
+	}
+
+	cancel()
+	cancel()
+	if _, open := <-events; open {
+		t.Error("events channel not closed by cancel")
+	}
+	m.Set(4, "after cancel")
+	if m.watch.watchers.Load() != nil {
+		t.Error("watcher not removed by cancel")
+	}
+}
+
+func TestWatchPolicies(t *testing.T) {
+	t.Run("Drop", func(t *testing.T) {
+		m := NewConcurrent[int, int]()
+		events, cancel := m.Watch(nil, WithWatchBuffer(2))
+		defer cancel()
+		for i := range 5 {
+			m.Set(i, i)
+		}
+		if len(events) != 2 || receive(t, events).Key != 0 || receive(t, events).Key != 1 {
+			t.Error("the first events must be kept")
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		m := NewConcurrent[int, int]()
+		events, cancel := m.Watch(nil, WithWatchBuffer(0), WithWatchPolicy(BlockWriters))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := range 3 {
+				m.Set(i, i)
+			}
+		}()
+		for i := range 2 {
+			if event := receive(t, events); event.Key != i {
+				t.Errorf("invalid event key. expected=%d, got=%d", i, event.Key)
+			}
+		}
+		select {
+		case <-done:
+			t.Fatal("writer not blocked by the watcher")
+		case <-time.After(10 * time.Millisecond):
+		}
+		cancel()
+		<-done // Cancellation unblocks writers
+	})
+
+	t.Run("Coalesce", func(t *testing.T) {
+		m := NewConcurrent[int, int]()
+		m.Set(2, 1)
+		events, cancel := m.Watch(nil, WithWatchBuffer(0), WithWatchPolicy(CoalesceEvents))
+		defer cancel()
+		w := (*m.watch.watchers.Load())[0]
+
+		// Wait for the forwarding goroutine to hold the first event, next ones stay pending
+		m.Set(0, 0)
+		for {
+			w.mu.Lock()
+			pending := len(w.pending)
+			w.mu.Unlock()
+			if pending == 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		m.Set(1, 1)
+		m.Set(2, 5)
+		m.Set(1, 2)
+		m.Set(3, 1)
+		m.Delete(3)
+		m.Set(2, 2)
+		m.Delete(2)
+		m.SetWithTTL(4, 1, -time.Second)
+		m.DeleteExpired() // Cancels the insertion
+
+		expected := []Event[int, int]{
+			{Kind: EventSet, Key: 0, NewValue: 0},
+			{Kind: EventSet, Key: 1, NewValue: 2},
+			{Kind: EventDelete, Key: 2, OldValue: 1, Existed: true},
+		}
+		for _, expectedEvent := range expected {
+			if event := receive(t, events); event != expectedEvent {
+				t.Errorf("invalid event. expected=%+v, got=%+v", expectedEvent, event)
+			}
+		}
+		select {
+		case event := <-events:
+			t.Errorf("unexpected event: %+v", event)
+		case <-time.After(10 * time.Millisecond):
+		}
+	})
+
+	t.Run("CoalesceClear", func(t *testing.T) {
+		m := NewConcurrent[int, int]()
+		events, cancel := m.Watch(nil, WithWatchBuffer(0), WithWatchPolicy(CoalesceEvents))
+		defer cancel()
+		w := (*m.watch.watchers.Load())[0]
+
+		// Wait for the forwarding goroutine to hold the first event, next ones stay pending
+		m.Set(-1, 0)
+		for {
+			w.mu.Lock()
+			pending := len(w.pending)
+			w.mu.Unlock()
+			if pending == 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		m.Set(0, 0)
+		m.Set(1, 1)
+		m.Clear()
+		m.Set(2, 2)
+
+		expected := []Event[int, int]{
+			{Kind: EventSet, Key: -1, NewValue: 0},
+			{Kind: EventClear},
+			{Kind: EventSet, Key: 2, NewValue: 2},
+		}
+		for _, expectedEvent := range expected {
+			if event := receive(t, events); event != expectedEvent {
+				t.Errorf("invalid event. expected=%+v, got=%+v", expectedEvent, event)
+			}
+		}
+	})
+}
+
+// Cancel watches while goroutines write, all sent events must be received in order per writer.
+func TestWatchConcurrent(t *testing.T) {
+	const writers = 4
+	const writes = 2_000
+	m := NewConcurrent[int, int]()
+	for _, policy := range []WatchPolicy{DropEvents, BlockWriters, CoalesceEvents} {
+		events, cancel := m.Watch(nil, WithWatchPolicy(policy))
+		go func() {
+			for range events {
+			}
+		}()
+		defer cancel()
+	}
+	events, cancel := m.Watch(nil, WithWatchPolicy(BlockWriters))
+
+	var wg sync.WaitGroup
+	for writer := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range writes {
+				m.Set(writer, i)
+				if i%100 == 0 {
+					_, cancel := m.Watch(nil, WithWatchBuffer(1), WithWatchPolicy(BlockWriters))
+					cancel()
+				}
+			}
+		}()
+	}
+
+	last := map[int]int{}
+	for range writers * writes {
+		event := receive(t, events)
+		if previous, found := last[event.Key]; found && event.NewValue != previous+1 {
+			t.Fatalf("invalid event order for writer=%d. expected=%d, got=%d", event.Key, previous+1, event.NewValue)
+		}
+		last[event.Key] = event.NewValue
+	}
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,6 @@
+//go:build !hashmapnocheck
+
+package hashmap
+
+// Concurrent misuse detection is enabled, unless the hashmapnocheck build tag is set.
+const misuseChecks = true
@@ -0,0 +1,106 @@
+package hashmap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/valsov/hashmap/hasher"
+)
+
+func TestMisuseDetection(t *testing.T) {
+	if !misuseChecks {
+		t.Skip("misuse detection disabled by the hashmapnocheck build tag")
+	}
+
+	cases := []struct {
+		name     string
+		op       func(m *Hashmap[int, int])
+		expected string
+	}{
+		{"Set", func(m *Hashmap[int, int]) { m.Set(1, 1) }, "concurrent hashmap writes"},
+		{"Delete", func(m *Hashmap[int, int]) { m.Delete(1) }, "concurrent hashmap writes"},
+		{"Clear", func(m *Hashmap[int, int]) { m.Clear() }, "concurrent hashmap writes"},
+		{"SetMany", func(m *Hashmap[int, int]) { m.SetMany([]int{1}, []int{1}) }, "concurrent hashmap writes"},
+		{"Get", func(m *Hashmap[int, int]) { m.Get(1) }, "concurrent hashmap read and hashmap write"},
+		{"GetEntries", func(m *Hashmap[int, int]) { m.GetEntries() }, "concurrent hashmap read and hashmap write"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := New[int, int]()
+			m.Set(1, 1)
+			m.writing = true // Simulate a write in progress in another goroutine
+
+			defer func() {
+				message, _ := recover().(string)
+				if !strings.Contains(message, c.expected) {
+					t.Errorf("invalid panic. expected=%q, got=%q", c.expected, message)
+				}
+			}()
+			c.op(m)
+		})
+	}
+}
+
+// A write ending during another one, such as a growth, must be detected.
+func TestMisuseDetectionDuringGrowth(t *testing.T) {
+	if !misuseChecks {
+		t.Skip("misuse detection disabled by the hashmapnocheck build tag")
+	}
+
+	concurrentWrite := false
+	hashes := 0
+	hashFunc := hasher.GetHashFunc[int]()
+	var m *Hashmap[int, int]
+	m = New(WithInitialCapacity[int, int](2), WithHashFunc[int, int](func(key, seed uintptr) uintptr {
+		if concurrentWrite {
+			// The first hash is the one of the written key, the next ones rehash entries during the growth
+			if hashes++; hashes == 2 {
+				concurrentWrite = false
+				m.writing = false // Simulate another goroutine ending its write
+			}
+		}
+		return hashFunc(key, seed)
+	}))
+	m.Set(1, 1)
+
+	defer func() {
+		if message, _ := recover().(string); !strings.Contains(message, "concurrent hashmap writes") {
+			t.Errorf("invalid panic. got=%q", message)
+		}
+	}()
+	concurrentWrite = true
+	m.Set(2, 2) // Grows
+}
+
+// A panic while hashing a key must not leave the hashmap in the writing state.
+func TestMisuseDetectionAfterHashPanic(t *testing.T) {
+	unhashable := []int{1}
+	ops := []struct {
+		name string
+		op   func(m *Hashmap[any, int])
+	}{
+		{"Set", func(m *Hashmap[any, int]) { m.Set(unhashable, 1) }},
+		{"Delete", func(m *Hashmap[any, int]) { m.Delete(unhashable) }},
+		{"SetMany", func(m *Hashmap[any, int]) { m.SetMany([]any{2, unhashable}, []int{2, 2}) }},
+	}
+	for _, op := range ops {
+		t.Run(op.name, func(t *testing.T) {
+			var m Hashmap[any, int]
+			m.Set(1, 1)
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Error("hashing an unhashable key must panic")
+					}
+				}()
+				op.op(&m)
+			}()
+
+			m.Set(3, 3)
+			m.Delete(1)
+			if m.Get(3) != 3 || m.Len() < 1 {
+				t.Errorf("hashmap unusable after a hash panic: %v", m.GetEntries())
+			}
+		})
+	}
+}
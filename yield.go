@@ -0,0 +1,7 @@
+//go:build !hashmapstress
+
+package hashmap
+
+// Give other goroutines a chance to run between the steps of a concurrent operation, only enabled by the hashmapstress
+// build tag to exercise more interleavings in tests.
+func yieldStep() {}
@@ -0,0 +1,16 @@
+//go:build hashmapstress
+
+package hashmap
+
+import (
+	"math/rand/v2"
+	"runtime"
+)
+
+// Give other goroutines a chance to run between the steps of a concurrent operation, to exercise more interleavings
+// in tests, even on a single CPU.
+func yieldStep() {
+	if rand.IntN(4) == 0 {
+		runtime.Gosched()
+	}
+}